@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mikejoh/go-credly/internal/credly"
+	"github.com/mikejoh/go-credly/internal/readme"
+)
+
+// defaultOptions are the section options used unless configured otherwise.
+var defaultOptions = readme.SectionOptions{
+	Layout:  readme.LayoutImages,
+	Columns: 4,
+	Size:    "110",
+	Expired: readme.ExpiredShow,
+}
+
+// config is the optional JSON configuration file. The top level options
+// apply to every section, settings provided as flags or action inputs take
+// precedence over them, and the options of a section take precedence over
+// both.
+type config struct {
+	readme.SectionOptions
+	TemplateFile string          `json:"template_file,omitempty"`
+	Sections     []sectionConfig `json:"sections,omitempty"`
+}
+
+type sectionConfig struct {
+	Name string `json:"name"`
+	readme.SectionOptions
+	TemplateFile string `json:"template_file,omitempty"`
+}
+
+func parseConfig(content string) (*config, error) {
+	var cfg config
+	if err := json.Unmarshal([]byte(content), &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse configuration: %w", err)
+	}
+
+	for i, section := range cfg.Sections {
+		if section.Name == "" {
+			return nil, fmt.Errorf("failed to parse configuration: section %d has no name", i+1)
+		}
+	}
+
+	return &cfg, nil
+}
+
+// fileFetcher fetches files from the repository holding the readme.
+type fileFetcher interface {
+	FetchFile(ctx context.Context, path string) (string, error)
+}
+
+// sections returns the configured sections with the provided options as
+// defaults. If no sections are configured every section found in the readme
+// is used, falling back to the default section.
+func (cfg *config) sections(ctx context.Context, files fileFetcher, options readme.SectionOptions, found []string) ([]readme.Section, error) {
+	if len(cfg.Sections) == 0 {
+		if len(found) == 0 {
+			found = []string{readme.DefaultSection}
+		}
+
+		sections := make([]readme.Section, 0, len(found))
+		for _, name := range found {
+			sections = append(sections, readme.Section{Name: name, Options: options})
+		}
+
+		return sections, nil
+	}
+
+	sections := make([]readme.Section, 0, len(cfg.Sections))
+	for _, sc := range cfg.Sections {
+		sectionOptions, err := withTemplateFile(ctx, files, sc.SectionOptions, sc.TemplateFile)
+		if err != nil {
+			return nil, err
+		}
+
+		sections = append(sections, readme.Section{
+			Name:    sc.Name,
+			Options: options.Merge(sectionOptions),
+		})
+	}
+
+	return sections, nil
+}
+
+// withTemplateFile sets the template of the options to the content of the
+// template file, if any.
+func withTemplateFile(ctx context.Context, files fileFetcher, options readme.SectionOptions, templateFile string) (readme.SectionOptions, error) {
+	if templateFile == "" {
+		return options, nil
+	}
+
+	template, err := files.FetchFile(ctx, templateFile)
+	if err != nil {
+		return options, fmt.Errorf("failed to fetch template file %s: %w", templateFile, err)
+	}
+
+	options.Template = template
+
+	return options, nil
+}
+
+// sectionOptions returns the section options provided as flags or action
+// inputs.
+func (cdOpts *credlyBadgesOptions) sectionOptions() readme.SectionOptions {
+	return readme.SectionOptions{
+		Layout:   cdOpts.layout,
+		Template: cdOpts.template,
+		Columns:  cdOpts.columns,
+		Size:     cdOpts.size,
+		Sort:     cdOpts.sort,
+		Expired:  cdOpts.expired,
+		Filter:   cdOpts.filter.filter(),
+	}
+}
+
+// stringList is a flag.Value holding a comma separated list of strings, the
+// flag may also be repeated.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(s string) error {
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*l = append(*l, v)
+		}
+	}
+	return nil
+}
+
+// filterOptions holds the badge filter flags.
+type filterOptions struct {
+	includeIssuers    stringList
+	excludeIssuers    stringList
+	includeCategories stringList
+	excludeCategories stringList
+	includeLevels     stringList
+	excludeLevels     stringList
+	includeName       string
+	excludeName       string
+	includeIDs        stringList
+	excludeIDs        stringList
+}
+
+// loadEnv sets the filter options not provided as flags from the matching
+// action inputs.
+func (fo *filterOptions) loadEnv() {
+	lists := map[string]*stringList{
+		"INPUT_INCLUDE_ISSUERS":    &fo.includeIssuers,
+		"INPUT_EXCLUDE_ISSUERS":    &fo.excludeIssuers,
+		"INPUT_INCLUDE_CATEGORIES": &fo.includeCategories,
+		"INPUT_EXCLUDE_CATEGORIES": &fo.excludeCategories,
+		"INPUT_INCLUDE_LEVELS":     &fo.includeLevels,
+		"INPUT_EXCLUDE_LEVELS":     &fo.excludeLevels,
+		"INPUT_INCLUDE_IDS":        &fo.includeIDs,
+		"INPUT_EXCLUDE_IDS":        &fo.excludeIDs,
+	}
+	for env, list := range lists {
+		if len(*list) == 0 {
+			_ = list.Set(os.Getenv(env))
+		}
+	}
+
+	if fo.includeName == "" {
+		fo.includeName = os.Getenv("INPUT_INCLUDE_NAME")
+	}
+
+	if fo.excludeName == "" {
+		fo.excludeName = os.Getenv("INPUT_EXCLUDE_NAME")
+	}
+}
+
+func (fo *filterOptions) filter() credly.Filter {
+	return credly.Filter{
+		IncludeIssuers:    fo.includeIssuers,
+		ExcludeIssuers:    fo.excludeIssuers,
+		IncludeCategories: fo.includeCategories,
+		ExcludeCategories: fo.excludeCategories,
+		IncludeLevels:     fo.includeLevels,
+		ExcludeLevels:     fo.excludeLevels,
+		IncludeName:       fo.includeName,
+		ExcludeName:       fo.excludeName,
+		IncludeIDs:        fo.includeIDs,
+		ExcludeIDs:        fo.excludeIDs,
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"os"
+
+	"github.com/mikejoh/go-credly/internal/credly"
+	"github.com/mikejoh/go-credly/internal/readme"
+)
+
+// Exit codes, so that scripts running the action over many profiles can tell
+// why a run failed.
+const (
+	exitError        = 1
+	exitChanges      = 2
+	exitConflict     = 3
+	exitNotFound     = 4
+	exitUnauthorized = 5
+	exitRateLimited  = 6
+	exitMarkers      = 7
+)
+
+// exitCode returns the exit code for the error.
+func exitCode(err error) int {
+	var markerErr *readme.MarkerError
+
+	switch {
+	case errors.Is(err, readme.ErrConflict):
+		return exitConflict
+	case errors.Is(err, readme.ErrNotFound), errors.Is(err, credly.ErrNotFound):
+		return exitNotFound
+	case errors.Is(err, readme.ErrUnauthorized), errors.Is(err, credly.ErrUnauthorized):
+		return exitUnauthorized
+	case errors.Is(err, readme.ErrRateLimited), errors.Is(err, credly.ErrRateLimited):
+		return exitRateLimited
+	case errors.As(err, &markerErr):
+		return exitMarkers
+	}
+
+	return exitError
+}
+
+// fatal logs the error and exits with its exit code.
+func fatal(err error) {
+	log.Print(err)
+	os.Exit(exitCode(err))
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/mikejoh/go-credly/internal/credly"
+	"github.com/mikejoh/go-credly/internal/readme"
+)
+
+func TestExitCode(t *testing.T) {
+	tt := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{
+			name:     "other error",
+			err:      errors.New("boom"),
+			expected: exitError,
+		},
+		{
+			name:     "conflict",
+			err:      fmt.Errorf("failed to update README.md: %w", readme.ErrConflict),
+			expected: exitConflict,
+		},
+		{
+			name:     "readme not found",
+			err:      fmt.Errorf("404 Not Found: %w", readme.ErrNotFound),
+			expected: exitNotFound,
+		},
+		{
+			name:     "credly user not found",
+			err:      fmt.Errorf("failed to fetch Credly user (alice) page: %w", credly.ErrNotFound),
+			expected: exitNotFound,
+		},
+		{
+			name:     "forge unauthorized",
+			err:      fmt.Errorf("401 Bad credentials: %w", readme.ErrUnauthorized),
+			expected: exitUnauthorized,
+		},
+		{
+			name:     "credly unauthorized",
+			err:      fmt.Errorf("failed to fetch Credly user (alice) badges: %w", credly.ErrUnauthorized),
+			expected: exitUnauthorized,
+		},
+		{
+			name:     "forge rate limited",
+			err:      fmt.Errorf("403 API rate limit exceeded: %w", readme.ErrRateLimited),
+			expected: exitRateLimited,
+		},
+		{
+			name:     "credly rate limited",
+			err:      fmt.Errorf("failed to fetch Credly user (alice) badges: %w", credly.ErrRateLimited),
+			expected: exitRateLimited,
+		},
+		{
+			name:     "missing markers",
+			err:      &readme.MarkerError{Kind: readme.MissingStart, Section: "badges"},
+			expected: exitMarkers,
+		},
+		{
+			name:     "wrapped invalid markers",
+			err:      fmt.Errorf("alice: %w", &readme.MarkerError{Kind: readme.DuplicateEnd, Section: "badges", Line: 4, OtherLine: 2}),
+			expected: exitMarkers,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if code := exitCode(tc.err); code != tc.expected {
+				t.Fatalf("expected exit code %d, got %d", tc.expected, code)
+			}
+		})
+	}
+}
@@ -4,94 +4,624 @@ import (
 	"context"
 	"errors"
 	"flag"
+	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+
+	gh "github.com/google/go-github/v64/github"
 
 	"github.com/mikejoh/go-credly/internal/credly"
 	"github.com/mikejoh/go-credly/internal/readme"
 )
 
+const (
+	forgeGitHub = "github"
+	forgeGitLab = "gitlab"
+	forgeGitea  = "gitea"
+)
+
 type credlyBadgesOptions struct {
 	credlyUsername string
 	ghToken        string
 	ghUsername     string
 	branch         string
 	commitMessage  string
+	authorName     string
+	authorEmail    string
+	committerName  string
+	committerEmail string
+	signingCommand string
+	verified       bool
+	dryRun         bool
+	retries        int
+	template       string
+	templateFile   string
+	layout         string
+	columns        int
+	size           string
+	sort           string
+	configFile     string
+	filter         filterOptions
+	expired        string
+	localFile      string
+	forge          string
+	gitlabToken    string
+	gitlabUsername string
+	gitlabURL      string
+	giteaToken     string
+	giteaUsername  string
+	giteaURL       string
+	pullRequest    string
+	owner          string
+	repo           string
+	file           string
+	manifest       string
+	concurrency    int
 }
 
 func main() {
 	cdOpts := &credlyBadgesOptions{}
 
+	// Invalid flags exit with exitError rather than the 2 of flag.ExitOnError,
+	// which is the exit code of a dry run that would change the readme.
+	flag.CommandLine.Init(os.Args[0], flag.ContinueOnError)
+
 	flag.StringVar(&cdOpts.credlyUsername, "credly-username", "", "Credly username")
 	flag.StringVar(&cdOpts.ghToken, "gh-token", "", "GitHub token")
 	flag.StringVar(&cdOpts.ghUsername, "gh-username", "", "GitHub username")
-	flag.StringVar(&cdOpts.branch, "branch", "main", "Branch to commit the changes")
-	flag.StringVar(&cdOpts.commitMessage, "commit-message", "Update Credly badges!", "Commit message")
-	flag.Parse()
+	flag.StringVar(&cdOpts.branch, "branch", "", "Branch to commit the changes (default main)")
+	flag.StringVar(&cdOpts.commitMessage, "commit-message", "", "Go text/template of the commit message, with .Added, .Removed and .Badges (default \""+readme.DefaultMessage+"\")")
+	flag.StringVar(&cdOpts.authorName, "author-name", "", "Name of the commit author (default github-actions[bot] on GitHub)")
+	flag.StringVar(&cdOpts.authorEmail, "author-email", "", "Email of the commit author")
+	flag.StringVar(&cdOpts.committerName, "committer-name", "", "Name of the committer (default github-actions[bot] on GitHub)")
+	flag.StringVar(&cdOpts.committerEmail, "committer-email", "", "Email of the committer")
+	flag.StringVar(&cdOpts.signingCommand, "signing-command", "", "Command signing commits, given the commit on stdin and writing an armored signature to stdout, e.g. \"gpg --batch --detach-sign --armor\" (GitHub only)")
+	flag.BoolVar(&cdOpts.verified, "verified", false, "Leave the commit author and committer to GitHub, so that commits made with a GitHub App installation token are verified (GitHub only)")
+	flag.StringVar(&cdOpts.template, "template", "", "Go text/template used to render the badges")
+	flag.StringVar(&cdOpts.templateFile, "template-file", "", "Path to a file in the repository holding the Go text/template used to render the badges")
+	flag.StringVar(&cdOpts.layout, "layout", "", "Badge layout, one of "+strings.Join(readme.Layouts(), ", ")+" (default images)")
+	flag.IntVar(&cdOpts.columns, "columns", 0, "Number of columns used by the table layout (default 4)")
+	flag.StringVar(&cdOpts.size, "size", "", "Badge image size in pixels, or original (default 110)")
+	flag.StringVar(&cdOpts.sort, "sort", "", "Sort badges by issued, expires, name, issuer or level, prefix with - for descending order")
+	flag.StringVar(&cdOpts.configFile, "config", "", "Path to a JSON configuration file in the repository")
+	flag.Var(&cdOpts.filter.includeIssuers, "include-issuers", "Comma separated list of issuers to include")
+	flag.Var(&cdOpts.filter.excludeIssuers, "exclude-issuers", "Comma separated list of issuers to exclude")
+	flag.Var(&cdOpts.filter.includeCategories, "include-categories", "Comma separated list of badge categories to include, e.g. Certification")
+	flag.Var(&cdOpts.filter.excludeCategories, "exclude-categories", "Comma separated list of badge categories to exclude, e.g. Learning")
+	flag.Var(&cdOpts.filter.includeLevels, "include-levels", "Comma separated list of badge levels to include")
+	flag.Var(&cdOpts.filter.excludeLevels, "exclude-levels", "Comma separated list of badge levels to exclude")
+	flag.StringVar(&cdOpts.filter.includeName, "include-name", "", "Regular expression matching the names of the badges to include")
+	flag.StringVar(&cdOpts.filter.excludeName, "exclude-name", "", "Regular expression matching the names of the badges to exclude")
+	flag.Var(&cdOpts.filter.includeIDs, "include-ids", "Comma separated list of badge ids to include")
+	flag.Var(&cdOpts.filter.excludeIDs, "exclude-ids", "Comma separated list of badge ids to exclude")
+	flag.StringVar(&cdOpts.expired, "expired", "", "How to render expired badges: show, hide, mark or separate-section (default show)")
+	flag.StringVar(&cdOpts.localFile, "local-file", "", "Path to a local readme file to update instead of using the GitHub API")
+	flag.StringVar(&cdOpts.forge, "forge", "", "Forge hosting the profile readme, github, gitlab or gitea (default github)")
+	flag.StringVar(&cdOpts.gitlabToken, "gitlab-token", "", "GitLab token")
+	flag.StringVar(&cdOpts.gitlabUsername, "gitlab-username", "", "GitLab username")
+	flag.StringVar(&cdOpts.gitlabURL, "gitlab-url", "", "GitLab API URL, e.g. https://gitlab.example.com/api/v4/ (default https://gitlab.com/api/v4/)")
+	flag.StringVar(&cdOpts.giteaToken, "gitea-token", "", "Gitea or Forgejo token")
+	flag.StringVar(&cdOpts.giteaUsername, "gitea-username", "", "Gitea or Forgejo username")
+	flag.StringVar(&cdOpts.giteaURL, "gitea-url", "", "URL of the Gitea or Forgejo instance, e.g. https://gitea.example.com")
+	flag.StringVar(&cdOpts.pullRequest, "pull-request", "", "Commit to this branch and open a pull request instead of committing to the branch directly (GitHub only)")
+	flag.BoolVar(&cdOpts.dryRun, "dry-run", false, fmt.Sprintf("Print a unified diff of the readme instead of updating it, exits with %d if the readme would change", exitChanges))
+	flag.IntVar(&cdOpts.retries, "retries", -1, "Number of times to refetch the readme and retry the update if it changed since it was fetched (default 3)")
+	flag.StringVar(&cdOpts.owner, "owner", "", "Owner of the repository holding the readme, a user or an organization (default the forge username)")
+	flag.StringVar(&cdOpts.repo, "repo", "", "Repository holding the readme (default the owner, i.e. the profile repository)")
+	flag.StringVar(&cdOpts.file, "file", "", "Path of the readme in the repository (default README.md)")
+	flag.StringVar(&cdOpts.manifest, "manifest", "", "Path to a local YAML or JSON manifest listing the readmes to update in one run")
+	flag.IntVar(&cdOpts.concurrency, "concurrency", 0, fmt.Sprintf("Number of manifest entries updated at the same time (default %d)", defaultConcurrency))
+	if err := flag.CommandLine.Parse(os.Args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
+		os.Exit(exitError)
+	}
+
+	if cdOpts.manifest == "" {
+		cdOpts.manifest = os.Getenv("INPUT_MANIFEST")
+	}
+
+	if concurrency := os.Getenv("INPUT_CONCURRENCY"); concurrency != "" && cdOpts.concurrency == 0 {
+		n, err := strconv.Atoi(concurrency)
+		if err != nil {
+			log.Fatalf("invalid concurrency %q: %v", concurrency, err)
+		}
+		cdOpts.concurrency = n
+	}
+
+	// Manifest entries provide their own Credly username, owner and local
+	// file, and the forge credentials are checked for each entry.
+	batch := cdOpts.manifest != ""
+
+	if cdOpts.localFile == "" {
+		cdOpts.localFile = os.Getenv("INPUT_LOCAL_FILE")
+	}
+
+	if batch && cdOpts.localFile != "" {
+		log.Fatal("local file mode cannot be combined with a manifest, set local_file in the manifest instead")
+	}
+
+	if !cdOpts.dryRun {
+		cdOpts.dryRun = os.Getenv("INPUT_DRY_RUN") == "true"
+	}
+
+	if cdOpts.forge == "" {
+		cdOpts.forge = os.Getenv("INPUT_FORGE")
+		if cdOpts.forge == "" {
+			cdOpts.forge = forgeGitHub
+		}
+	}
+
+	if cdOpts.forge != forgeGitHub && cdOpts.forge != forgeGitLab && cdOpts.forge != forgeGitea {
+		log.Fatalf("unknown forge %q, must be one of %s, %s or %s", cdOpts.forge, forgeGitHub, forgeGitLab, forgeGitea)
+	}
+
+	github := cdOpts.forge == forgeGitHub && cdOpts.localFile == ""
+	gitlab := cdOpts.forge == forgeGitLab && cdOpts.localFile == ""
+	gitea := cdOpts.forge == forgeGitea && cdOpts.localFile == ""
+
+	if cdOpts.owner == "" {
+		cdOpts.owner = os.Getenv("INPUT_OWNER")
+	}
+
+	if cdOpts.repo == "" {
+		cdOpts.repo = os.Getenv("INPUT_REPO")
+	}
+
+	if cdOpts.file == "" {
+		cdOpts.file = os.Getenv("INPUT_FILE")
+		if cdOpts.file == "" {
+			cdOpts.file = "README.md"
+		}
+	}
 
 	if cdOpts.credlyUsername == "" {
 		cdOpts.credlyUsername = os.Getenv("INPUT_CREDLY_USERNAME")
-		if cdOpts.credlyUsername == "" {
+		if cdOpts.credlyUsername == "" && !batch {
 			log.Fatal("Username is not provided. Please provide it as a command-line argument or set the CREDLY_USERNAME environment variable.")
 		}
 	}
 
-	if cdOpts.ghToken == "" {
+	if cdOpts.ghToken == "" && github {
 		cdOpts.ghToken = os.Getenv("INPUT_GITHUB_TOKEN")
-		if cdOpts.ghToken == "" {
+		if cdOpts.ghToken == "" && !batch {
 			log.Fatal("GitHub token is not provided. Please provide it as a command-line argument or set the GITHUB_TOKEN environment variable.")
 		}
 	}
 
-	if cdOpts.ghUsername == "" {
+	if cdOpts.ghUsername == "" && cdOpts.owner == "" && !batch && github {
 		cdOpts.ghUsername = os.Getenv("GITHUB_ACTOR")
 		if cdOpts.ghUsername == "" {
 			log.Fatal("GitHub username is not provided. Please provide it as a command-line argument or set the GITHUB_USERNAME environment variable.")
 		}
 	}
 
+	if cdOpts.gitlabToken == "" && gitlab {
+		cdOpts.gitlabToken = os.Getenv("INPUT_GITLAB_TOKEN")
+		if cdOpts.gitlabToken == "" && !batch {
+			log.Fatal("GitLab token is not provided. Please provide it as a command-line argument or set the GITLAB_TOKEN environment variable.")
+		}
+	}
+
+	if cdOpts.gitlabUsername == "" && cdOpts.owner == "" && !batch && gitlab {
+		cdOpts.gitlabUsername = os.Getenv("INPUT_GITLAB_USERNAME")
+		if cdOpts.gitlabUsername == "" {
+			log.Fatal("GitLab username is not provided. Please provide it as a command-line argument or set the GITLAB_USERNAME environment variable.")
+		}
+	}
+
+	if cdOpts.gitlabURL == "" {
+		cdOpts.gitlabURL = os.Getenv("INPUT_GITLAB_URL")
+	}
+
+	if cdOpts.giteaToken == "" && gitea {
+		cdOpts.giteaToken = os.Getenv("INPUT_GITEA_TOKEN")
+		if cdOpts.giteaToken == "" && !batch {
+			log.Fatal("Gitea token is not provided. Please provide it as a command-line argument or set the GITEA_TOKEN environment variable.")
+		}
+	}
+
+	if cdOpts.giteaUsername == "" && cdOpts.owner == "" && !batch && gitea {
+		cdOpts.giteaUsername = os.Getenv("INPUT_GITEA_USERNAME")
+		if cdOpts.giteaUsername == "" {
+			log.Fatal("Gitea username is not provided. Please provide it as a command-line argument or set the GITEA_USERNAME environment variable.")
+		}
+	}
+
+	if cdOpts.giteaURL == "" && gitea {
+		cdOpts.giteaURL = os.Getenv("INPUT_GITEA_URL")
+		if cdOpts.giteaURL == "" && !batch {
+			log.Fatal("Gitea URL is not provided. Please provide it as a command-line argument or set the GITEA_URL environment variable.")
+		}
+	}
+
+	if cdOpts.owner == "" {
+		switch {
+		case gitlab:
+			cdOpts.owner = cdOpts.gitlabUsername
+		case gitea:
+			cdOpts.owner = cdOpts.giteaUsername
+		default:
+			cdOpts.owner = cdOpts.ghUsername
+		}
+	}
+
+	if cdOpts.repo == "" && !batch {
+		cdOpts.repo = cdOpts.owner
+	}
+
+	if cdOpts.pullRequest == "" {
+		cdOpts.pullRequest = os.Getenv("INPUT_PULL_REQUEST")
+	}
+
+	if cdOpts.pullRequest != "" && !github {
+		log.Fatal("pull request mode is only supported when committing to GitHub")
+	}
+
 	if cdOpts.branch == "" {
-		cdOpts.branch = "main"
+		cdOpts.branch = os.Getenv("INPUT_BRANCH")
+		if cdOpts.branch == "" {
+			cdOpts.branch = "main"
+		}
+	}
+
+	if cdOpts.commitMessage == "" {
+		cdOpts.commitMessage = os.Getenv("INPUT_COMMIT_MESSAGE")
+	}
+
+	if cdOpts.authorName == "" {
+		cdOpts.authorName = os.Getenv("INPUT_AUTHOR_NAME")
+	}
+
+	if cdOpts.authorEmail == "" {
+		cdOpts.authorEmail = os.Getenv("INPUT_AUTHOR_EMAIL")
+	}
+
+	if cdOpts.committerName == "" {
+		cdOpts.committerName = os.Getenv("INPUT_COMMITTER_NAME")
+	}
+
+	if cdOpts.committerEmail == "" {
+		cdOpts.committerEmail = os.Getenv("INPUT_COMMITTER_EMAIL")
 	}
 
+	if (cdOpts.authorName == "") != (cdOpts.authorEmail == "") {
+		log.Fatal("both the name and email of the commit author must be provided")
+	}
+
+	if (cdOpts.committerName == "") != (cdOpts.committerEmail == "") {
+		log.Fatal("both the name and email of the committer must be provided")
+	}
+
+	if cdOpts.signingCommand == "" {
+		cdOpts.signingCommand = os.Getenv("INPUT_SIGNING_COMMAND")
+	}
+
+	if !cdOpts.verified {
+		cdOpts.verified = os.Getenv("INPUT_VERIFIED") == "true"
+	}
+
+	if (cdOpts.signingCommand != "" || cdOpts.verified) && !github {
+		log.Fatal("signed commits are only supported when committing to GitHub")
+	}
+
+	if cdOpts.verified && (cdOpts.signingCommand != "" || cdOpts.authorName != "" || cdOpts.committerName != "") {
+		log.Fatal("verified commits are signed by GitHub and cannot be combined with a signing command or a commit author or committer")
+	}
+
+	if cdOpts.template == "" {
+		cdOpts.template = os.Getenv("INPUT_TEMPLATE")
+	}
+
+	if cdOpts.templateFile == "" {
+		cdOpts.templateFile = os.Getenv("INPUT_TEMPLATE_FILE")
+	}
+
+	if cdOpts.layout == "" {
+		cdOpts.layout = os.Getenv("INPUT_LAYOUT")
+	}
+
+	if columns := os.Getenv("INPUT_COLUMNS"); columns != "" && cdOpts.columns == 0 {
+		n, err := strconv.Atoi(columns)
+		if err != nil {
+			log.Fatalf("invalid number of columns %q: %v", columns, err)
+		}
+		cdOpts.columns = n
+	}
+
+	if cdOpts.size == "" {
+		cdOpts.size = os.Getenv("INPUT_SIZE")
+	}
+
+	if cdOpts.sort == "" {
+		cdOpts.sort = os.Getenv("INPUT_SORT")
+	}
+
+	if cdOpts.expired == "" {
+		cdOpts.expired = os.Getenv("INPUT_EXPIRED")
+	}
+
+	if retries := os.Getenv("INPUT_RETRIES"); retries != "" && cdOpts.retries < 0 {
+		n, err := strconv.Atoi(retries)
+		if err != nil {
+			log.Fatalf("invalid number of retries %q: %v", retries, err)
+		}
+		cdOpts.retries = n
+	}
+
+	if cdOpts.configFile == "" {
+		cdOpts.configFile = os.Getenv("INPUT_CONFIG_FILE")
+	}
+
+	cdOpts.filter.loadEnv()
+
 	ctx := context.Background()
 
 	credlyClient := credly.NewClient()
-	profileReadme := readme.NewReadme(cdOpts.ghUsername, cdOpts.ghUsername)
 
-	err := profileReadme.Fetch(ctx)
+	if cdOpts.manifest != "" {
+		os.Exit(runManifest(ctx, credlyClient, cdOpts))
+	}
+
+	res, err := run(ctx, credlyClient, cdOpts, target{
+		credlyUsername: cdOpts.credlyUsername,
+		owner:          cdOpts.owner,
+		repo:           cdOpts.repo,
+		file:           cdOpts.file,
+		branch:         cdOpts.branch,
+		localFile:      cdOpts.localFile,
+	})
+	if err != nil {
+		fatal(err)
+	}
+
+	if res.status == statusChanged {
+		fmt.Print(res.diff)
+		os.Exit(exitChanges)
+	}
+}
+
+// target is a readme to render the badges of a Credly user into.
+type target struct {
+	credlyUsername string
+	owner          string
+	repo           string
+	file           string
+	branch         string
+	localFile      string
+	// section restricts the update to the named section, if set.
+	section string
+	// options take precedence over the options provided as flags.
+	options readme.SectionOptions
+}
+
+// status is the outcome of updating a target.
+type status int
+
+const (
+	statusUnchanged status = iota
+	statusUpdated
+	// statusChanged is a dry run that would change the readme.
+	statusChanged
+)
+
+type runResult struct {
+	status status
+	diff   string
+}
+
+// newStorage returns the storage of the target readme on the configured
+// forge, or on disk in local file mode. The forge credentials are checked
+// here, as manifests may only list local files.
+func (cdOpts *credlyBadgesOptions) newStorage(t target) (readme.Storage, error) {
+	if t.localFile != "" {
+		return readme.NewLocalStorage(t.localFile), nil
+	}
+
+	if t.owner == "" {
+		return nil, errors.New("the owner of the repository is not provided")
+	}
+
+	switch cdOpts.forge {
+	case forgeGitLab:
+		if cdOpts.gitlabToken == "" {
+			return nil, errors.New("the GitLab token is not provided")
+		}
+		gitlabStorage := readme.NewGitLabStorage(t.owner, t.repo).
+			WithToken(cdOpts.gitlabToken).
+			WithFileName(t.file).
+			WithBranch(t.branch)
+		if cdOpts.gitlabURL != "" {
+			gitlabStorage.WithBaseURL(cdOpts.gitlabURL)
+		}
+		return gitlabStorage, nil
+	case forgeGitea:
+		if cdOpts.giteaToken == "" || cdOpts.giteaURL == "" {
+			return nil, errors.New("the Gitea token or URL is not provided")
+		}
+		return readme.NewGiteaStorage(cdOpts.giteaURL, t.owner, t.repo).
+			WithToken(cdOpts.giteaToken).
+			WithFileName(t.file).
+			WithBranch(t.branch), nil
+	}
+
+	if cdOpts.ghToken == "" {
+		return nil, errors.New("the GitHub token is not provided")
+	}
+
+	githubStorage := readme.NewGitHubStorage(t.owner, t.repo).
+		WithGitHubClient(gh.NewClient(nil).WithAuthToken(cdOpts.ghToken)).
+		WithFileName(t.file).
+		WithBranch(t.branch)
+	if cdOpts.pullRequest != "" {
+		githubStorage.WithPullRequest(cdOpts.pullRequest)
+	}
+	if cdOpts.signingCommand != "" {
+		signer, err := readme.ParseCommandSigner(cdOpts.signingCommand)
+		if err != nil {
+			return nil, err
+		}
+		githubStorage.WithSigner(signer)
+	}
+	if cdOpts.verified {
+		githubStorage.WithVerifiedCommits()
+	}
+
+	return githubStorage, nil
+}
+
+// run renders the badges of the Credly user into the target readme and
+// updates it, unless running dry.
+func run(ctx context.Context, credlyClient *credly.Credly, cdOpts *credlyBadgesOptions, t target) (runResult, error) {
+	storage, err := cdOpts.newStorage(t)
+	if err != nil {
+		return runResult{}, err
+	}
+
+	profileReadme := readme.New(storage)
+
+	if cdOpts.commitMessage != "" {
+		if _, err := profileReadme.WithMessage(cdOpts.commitMessage); err != nil {
+			return runResult{}, err
+		}
+	}
+
+	if cdOpts.retries >= 0 {
+		profileReadme.WithRetries(cdOpts.retries)
+	}
+
+	if cdOpts.authorName != "" {
+		profileReadme.WithAuthor(readme.Identity{Name: cdOpts.authorName, Email: cdOpts.authorEmail})
+	}
+
+	if cdOpts.committerName != "" {
+		profileReadme.WithCommitter(readme.Identity{Name: cdOpts.committerName, Email: cdOpts.committerEmail})
+	}
+
+	err = profileReadme.Fetch(ctx)
+	if err != nil {
+		return runResult{}, err
+	}
+
+	cfg := &config{}
+	if cdOpts.configFile != "" {
+		content, err := profileReadme.FetchFile(ctx, cdOpts.configFile)
+		if err != nil {
+			return runResult{}, fmt.Errorf("failed to fetch configuration file %s: %w", cdOpts.configFile, err)
+		}
+
+		cfg, err = parseConfig(content)
+		if err != nil {
+			return runResult{}, err
+		}
+	}
+
+	cfgOptions, err := withTemplateFile(ctx, profileReadme, cfg.SectionOptions, cfg.TemplateFile)
+	if err != nil {
+		return runResult{}, err
+	}
+
+	flagOptions, err := withTemplateFile(ctx, profileReadme, cdOpts.sectionOptions(), cdOpts.templateFile)
 	if err != nil {
-		log.Fatal(err)
+		return runResult{}, err
+	}
+
+	options := defaultOptions.Merge(cfgOptions).Merge(flagOptions).Merge(t.options)
+
+	found := profileReadme.SectionNames()
+	if t.section != "" {
+		found = []string{t.section}
 	}
 
-	body, err := credlyClient.FetchUserPage(ctx, cdOpts.credlyUsername)
+	sections, err := cfg.sections(ctx, profileReadme, options, found)
 	if err != nil {
-		log.Fatal(err)
+		return runResult{}, err
+	}
 
+	if t.section != "" {
+		sections = sectionNamed(sections, t.section, options)
 	}
 
-	badges, err := credly.ExtractBadges(body)
+	badges, err := fetchBadges(ctx, credlyClient, t.credlyUsername)
 	if err != nil {
-		log.Fatal(err)
+		return runResult{}, err
 	}
 
 	if len(badges) == 0 {
-		log.Fatalf("no badges found for the provided username %s. Exiting...", cdOpts.credlyUsername)
+		return runResult{}, fmt.Errorf("no badges found for the provided username %s", t.credlyUsername)
 	}
 
-	err = profileReadme.WriteBadges(badges)
+	err = profileReadme.WriteSections(badges, sections)
 	if err != nil {
 		if errors.Is(err, readme.ErrFilesAreEqual) {
 			log.Printf("no changes between the fetched %s and the updated detected. Exiting...", profileReadme.Filename())
-			return
+			return runResult{status: statusUnchanged}, nil
 		}
-		log.Fatal(err)
+		return runResult{}, err
 	}
 
-	err = profileReadme.Update(ctx, "main")
+	if cdOpts.dryRun {
+		log.Printf("dry run, %s not updated", profileReadme.Filename())
+		return runResult{status: statusChanged, diff: profileReadme.Diff()}, nil
+	}
+
+	err = profileReadme.Update(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return runResult{}, err
 	}
 
 	log.Printf("credly badges in %s updated successfully!", profileReadme.Filename())
+
+	return runResult{status: statusUpdated}, nil
+}
+
+// sectionNamed returns the named section among the sections, or the section
+// with the provided options if it is not configured.
+func sectionNamed(sections []readme.Section, name string, options readme.SectionOptions) []readme.Section {
+	for _, section := range sections {
+		if section.Name == name {
+			return []readme.Section{section}
+		}
+	}
+
+	return []readme.Section{{Name: name, Options: options}}
+}
+
+// fetchBadges fetches all badges of the Credly user from the JSON API, falling
+// back to the badge data embedded in the first page of the user profile, and
+// to scraping the page if it holds none.
+func fetchBadges(ctx context.Context, client *credly.Credly, username string) ([]credly.Badge, error) {
+	userBadges, err := client.FetchBadges(ctx, username)
+	if err == nil {
+		return credly.NewBadges(userBadges), nil
+	}
+
+	log.Printf("failed to fetch badges from the Credly API, falling back to the user page: %v", err)
+
+	body, err := client.FetchUserPage(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	return pageBadges(body)
+}
+
+// pageBadges returns the badges of the user page from the badge data it
+// embeds, falling back to scraping the page if it holds none.
+func pageBadges(body []byte) ([]credly.Badge, error) {
+	resp, err := credly.ExtractPrerenderData(body)
+	if err == nil {
+		return credly.NewBadges(resp.Data), nil
+	}
+
+	if !errors.Is(err, credly.ErrPrerenderDataNotFound) {
+		return nil, err
+	}
+
+	log.Printf("no badge data found in the user page, falling back to scraping it")
+
+	return credly.ExtractBadges(body)
 }
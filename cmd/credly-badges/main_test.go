@@ -0,0 +1,140 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/mikejoh/go-credly/internal/readme"
+)
+
+func TestPageBadges(t *testing.T) {
+	tt := []struct {
+		name      string
+		page      string
+		badgeName string
+		level     string
+		issued    string
+		err       bool
+	}{
+		{
+			name:      "prerender data",
+			page:      `<script>prerenderData[{"a":"/users/jane/badges"}.a] = JSON.parse({"a":"{\"data\":[{\"id\":\"1\",\"issued_at_date\":\"2024-01-02\",\"badge_template\":{\"name\":\"CKA\",\"level\":\"Intermediate\"}}]}"}.a);</script>`,
+			badgeName: "CKA",
+			level:     "Intermediate",
+			issued:    "2024-01-02",
+		},
+		{
+			name:      "scraped badges",
+			page:      `<div class="cr-standard-grid-item-content c-badge c-badge--medium"><div class="cr-standard-grid-item-content__title">KCNA</div></div>`,
+			badgeName: "KCNA",
+		},
+		{
+			name: "invalid prerender data",
+			page: `<script>prerenderData[{"a":"/users/jane/badges"}.a] = JSON.parse({"a":"{"}.a);</script>`,
+			err:  true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			badges, err := pageBadges([]byte(tc.page))
+			if tc.err {
+				if err == nil {
+					t.Fatalf("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if len(badges) != 1 {
+				t.Fatalf("expected 1 badge, got %d", len(badges))
+			}
+
+			badge := badges[0]
+			if badge.Name != tc.badgeName {
+				t.Fatalf("expected name %q, got %q", tc.badgeName, badge.Name)
+			}
+			if badge.Level != tc.level {
+				t.Fatalf("expected level %q, got %q", tc.level, badge.Level)
+			}
+
+			issued := ""
+			if !badge.IssuedAt.IsZero() {
+				issued = badge.IssuedAt.Format("2006-01-02")
+			}
+			if issued != tc.issued {
+				t.Fatalf("expected issued %q, got %q", tc.issued, issued)
+			}
+		})
+	}
+}
+
+func TestNewStorage(t *testing.T) {
+	tt := []struct {
+		name   string
+		cdOpts *credlyBadgesOptions
+		target target
+		local  bool
+		err    bool
+	}{
+		{
+			name:   "local file without token",
+			cdOpts: &credlyBadgesOptions{forge: forgeGitHub},
+			target: target{localFile: "README.md"},
+			local:  true,
+		},
+		{
+			name:   "github",
+			cdOpts: &credlyBadgesOptions{forge: forgeGitHub, ghToken: "token"},
+			target: target{owner: "acme", repo: "handbook", file: "README.md", branch: "main"},
+		},
+		{
+			name:   "github without token",
+			cdOpts: &credlyBadgesOptions{forge: forgeGitHub},
+			target: target{owner: "acme", repo: "handbook", file: "README.md", branch: "main"},
+			err:    true,
+		},
+		{
+			name:   "gitlab without token",
+			cdOpts: &credlyBadgesOptions{forge: forgeGitLab},
+			target: target{owner: "acme", repo: "handbook", file: "README.md", branch: "main"},
+			err:    true,
+		},
+		{
+			name:   "gitea without URL",
+			cdOpts: &credlyBadgesOptions{forge: forgeGitea, giteaToken: "token"},
+			target: target{owner: "acme", repo: "handbook", file: "README.md", branch: "main"},
+			err:    true,
+		},
+		{
+			name:   "no owner",
+			cdOpts: &credlyBadgesOptions{forge: forgeGitHub, ghToken: "token"},
+			target: target{file: "README.md", branch: "main"},
+			err:    true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			storage, err := tc.cdOpts.newStorage(tc.target)
+			if tc.err {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if _, ok := storage.(*readme.LocalStorage); ok != tc.local {
+				t.Fatalf("expected local storage %t, got %T", tc.local, storage)
+			}
+		})
+	}
+}
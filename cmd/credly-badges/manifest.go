@@ -0,0 +1,231 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/mikejoh/go-credly/internal/credly"
+	"github.com/mikejoh/go-credly/internal/readme"
+)
+
+// defaultConcurrency is the number of manifest entries updated at the same
+// time.
+const defaultConcurrency = 4
+
+// manifest lists the readmes to update in batch mode. Unset fields of an
+// entry fall back to the defaults of the manifest, and then to the flags.
+type manifest struct {
+	Concurrency int             `json:"concurrency,omitempty"`
+	Defaults    manifestEntry   `json:"defaults"`
+	Entries     []manifestEntry `json:"entries"`
+}
+
+type manifestEntry struct {
+	CredlyUsername string `json:"credly_username,omitempty"`
+	Owner          string `json:"owner,omitempty"`
+	Repo           string `json:"repo,omitempty"`
+	File           string `json:"file,omitempty"`
+	Branch         string `json:"branch,omitempty"`
+	Section        string `json:"section,omitempty"`
+	LocalFile      string `json:"local_file,omitempty"`
+	readme.SectionOptions
+}
+
+// parseManifest parses a YAML or JSON manifest, JSON being valid YAML.
+func parseManifest(content []byte) (*manifest, error) {
+	// Decode the YAML generically and convert it to JSON, so that the
+	// manifest shares the json field names of the configuration file.
+	var v any
+	if err := yaml.Unmarshal(content, &v); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+
+	var m manifest
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+
+	if len(m.Entries) == 0 {
+		return nil, fmt.Errorf("failed to parse manifest: no entries")
+	}
+
+	for i, entry := range m.Entries {
+		if entry.CredlyUsername == "" && m.Defaults.CredlyUsername == "" {
+			return nil, fmt.Errorf("failed to parse manifest: entry %d has no credly_username", i+1)
+		}
+	}
+
+	return &m, nil
+}
+
+// target returns the target of the entry, falling back to the defaults of
+// the manifest and then to the flags.
+func (m *manifest) target(entry manifestEntry, cdOpts *credlyBadgesOptions) target {
+	or := func(values ...string) string {
+		for _, v := range values {
+			if v != "" {
+				return v
+			}
+		}
+		return ""
+	}
+
+	d := m.Defaults
+	owner := or(entry.Owner, d.Owner, cdOpts.owner)
+
+	return target{
+		credlyUsername: or(entry.CredlyUsername, d.CredlyUsername),
+		owner:          owner,
+		repo:           or(entry.Repo, d.Repo, cdOpts.repo, owner),
+		file:           or(entry.File, d.File, cdOpts.file),
+		branch:         or(entry.Branch, d.Branch, cdOpts.branch),
+		localFile:      or(entry.LocalFile, d.LocalFile),
+		section:        or(entry.Section, d.Section),
+		options:        d.SectionOptions.Merge(entry.SectionOptions),
+	}
+}
+
+// String describes the target in the batch report.
+func (t target) String() string {
+	name := t.localFile
+	if name == "" {
+		name = t.owner + "/" + t.repo + "/" + t.file
+	}
+	if t.section != "" {
+		name += "#" + t.section
+	}
+
+	return t.credlyUsername + " -> " + name
+}
+
+// storageKey identifies the file updated for the target, on disk or on the forge.
+func (t target) storageKey() string {
+	if t.localFile != "" {
+		return filepath.Clean(t.localFile)
+	}
+
+	return t.owner + "/" + t.repo + "@" + t.branch + ":" + t.file
+}
+
+// groupTargets groups the indexes of the targets updating the same file, in
+// order of first appearance.
+func groupTargets(targets []target) [][]int {
+	var groups [][]int
+
+	index := make(map[string]int)
+	for i, t := range targets {
+		g, ok := index[t.storageKey()]
+		if !ok {
+			g = len(groups)
+			index[t.storageKey()] = g
+			groups = append(groups, nil)
+		}
+		groups[g] = append(groups[g], i)
+	}
+
+	return groups
+}
+
+// runManifest updates every entry of the manifest, a bounded number of files
+// at a time. Entries updating the same file run one after the other, so that
+// they do not overwrite each other. A failing entry does not stop the others.
+func runManifest(ctx context.Context, credlyClient *credly.Credly, cdOpts *credlyBadgesOptions) int {
+	content, err := os.ReadFile(cdOpts.manifest)
+	if err != nil {
+		log.Printf("failed to read manifest: %v", err)
+		return exitError
+	}
+
+	m, err := parseManifest(content)
+	if err != nil {
+		log.Print(err)
+		return exitError
+	}
+
+	concurrency := defaultConcurrency
+	switch {
+	case cdOpts.concurrency > 0:
+		concurrency = cdOpts.concurrency
+	case m.Concurrency > 0:
+		concurrency = m.Concurrency
+	}
+
+	targets := make([]target, len(m.Entries))
+	for i, entry := range m.Entries {
+		targets[i] = m.target(entry, cdOpts)
+	}
+
+	results := make([]runResult, len(targets))
+	errs := make([]error, len(targets))
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for _, group := range groupTargets(targets) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			for _, i := range group {
+				results[i], errs[i] = run(ctx, credlyClient, cdOpts, targets[i])
+			}
+		}()
+	}
+	wg.Wait()
+
+	return report(os.Stdout, targets, results, errs)
+}
+
+// report logs the outcome of every target followed by a summary, and writes
+// the diffs of dry runs to w. The exit code is the one of the first failure,
+// or exitChanges if a dry run would change a readme.
+func report(w io.Writer, targets []target, results []runResult, errs []error) int {
+	var updated, unchanged, changed, failed int
+	code := 0
+	for i, t := range targets {
+		if errs[i] != nil {
+			log.Printf("%s: failed: %v", t, errs[i])
+			if failed == 0 {
+				code = exitCode(errs[i])
+			}
+			failed++
+			continue
+		}
+
+		switch results[i].status {
+		case statusUpdated:
+			log.Printf("%s: updated", t)
+			updated++
+		case statusChanged:
+			log.Printf("%s: would change", t)
+			fmt.Fprint(w, results[i].diff)
+			changed++
+		default:
+			log.Printf("%s: up to date", t)
+			unchanged++
+		}
+	}
+
+	log.Printf("%d updated, %d up to date, %d would change, %d failed", updated, unchanged, changed, failed)
+
+	if code == 0 && changed > 0 {
+		code = exitChanges
+	}
+
+	return code
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/mikejoh/go-credly/internal/credly"
+	"github.com/mikejoh/go-credly/internal/readme"
+)
+
+func TestGroupTargets(t *testing.T) {
+	tt := []struct {
+		name     string
+		targets  []target
+		expected [][]int
+	}{
+		{
+			name: "different files",
+			targets: []target{
+				{owner: "acme", repo: "handbook", branch: "main", file: "alice.md"},
+				{owner: "acme", repo: "handbook", branch: "main", file: "bob.md"},
+			},
+			expected: [][]int{{0}, {1}},
+		},
+		{
+			name: "sections of the same file",
+			targets: []target{
+				{owner: "acme", repo: "handbook", branch: "main", file: "team.md", section: "alice"},
+				{owner: "acme", repo: "handbook", branch: "main", file: "alice.md"},
+				{owner: "acme", repo: "handbook", branch: "main", file: "team.md", section: "bob"},
+			},
+			expected: [][]int{{0, 2}, {1}},
+		},
+		{
+			name: "same file on another branch",
+			targets: []target{
+				{owner: "acme", repo: "handbook", branch: "main", file: "team.md"},
+				{owner: "acme", repo: "handbook", branch: "docs", file: "team.md"},
+			},
+			expected: [][]int{{0}, {1}},
+		},
+		{
+			name: "local files",
+			targets: []target{
+				{localFile: "docs/team.md", section: "alice"},
+				{localFile: "./docs/team.md", section: "bob"},
+			},
+			expected: [][]int{{0, 1}},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			groups := groupTargets(tc.targets)
+			if !reflect.DeepEqual(groups, tc.expected) {
+				t.Fatalf("expected %v, got %v", tc.expected, groups)
+			}
+		})
+	}
+}
+
+func TestParseManifest(t *testing.T) {
+	expected := &manifest{
+		Concurrency: 2,
+		Defaults:    manifestEntry{Owner: "acme", SectionOptions: readme.SectionOptions{Layout: "table"}},
+		Entries: []manifestEntry{
+			{CredlyUsername: "alice", File: "docs/alice.md"},
+			{
+				CredlyUsername: "bob",
+				Section:        "certifications",
+				SectionOptions: readme.SectionOptions{Filter: credly.Filter{IncludeCategories: []string{"Certification"}}},
+			},
+		},
+	}
+
+	tt := []struct {
+		name     string
+		content  string
+		expected *manifest
+		err      bool
+	}{
+		{
+			name: "yaml",
+			content: `concurrency: 2
+defaults:
+  owner: acme
+  layout: table
+entries:
+  - credly_username: alice
+    file: docs/alice.md
+  - credly_username: bob
+    section: certifications
+    filter:
+      include_categories: [Certification]
+`,
+			expected: expected,
+		},
+		{
+			name:     "json",
+			content:  `{"concurrency": 2, "defaults": {"owner": "acme", "layout": "table"}, "entries": [{"credly_username": "alice", "file": "docs/alice.md"}, {"credly_username": "bob", "section": "certifications", "filter": {"include_categories": ["Certification"]}}]}`,
+			expected: expected,
+		},
+		{
+			name:     "default credly username",
+			content:  "defaults:\n  credly_username: alice\nentries:\n  - repo: handbook\n",
+			expected: &manifest{Defaults: manifestEntry{CredlyUsername: "alice"}, Entries: []manifestEntry{{Repo: "handbook"}}},
+		},
+		{
+			name:    "no entries",
+			content: "concurrency: 2\n",
+			err:     true,
+		},
+		{
+			name:    "no credly username",
+			content: "entries:\n  - credly_username: alice\n  - owner: acme\n",
+			err:     true,
+		},
+		{
+			name:    "invalid yaml",
+			content: "entries: [",
+			err:     true,
+		},
+		{
+			name:    "invalid field type",
+			content: "concurrency: many\nentries:\n  - credly_username: alice\n",
+			err:     true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			m, err := parseManifest([]byte(tc.content))
+			if tc.err {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if !reflect.DeepEqual(m, tc.expected) {
+				t.Fatalf("expected %+v, got %+v", tc.expected, m)
+			}
+		})
+	}
+}
+
+func TestManifestTarget(t *testing.T) {
+	cdOpts := &credlyBadgesOptions{owner: "flag-owner", file: "README.md", branch: "main"}
+
+	tt := []struct {
+		name     string
+		defaults manifestEntry
+		entry    manifestEntry
+		cdOpts   *credlyBadgesOptions
+		expected target
+	}{
+		{
+			name:     "flags",
+			entry:    manifestEntry{CredlyUsername: "alice"},
+			cdOpts:   cdOpts,
+			expected: target{credlyUsername: "alice", owner: "flag-owner", repo: "flag-owner", file: "README.md", branch: "main"},
+		},
+		{
+			name:     "defaults over flags",
+			defaults: manifestEntry{CredlyUsername: "alice", Owner: "acme", File: "docs/team.md", Section: "team"},
+			entry:    manifestEntry{},
+			cdOpts:   cdOpts,
+			expected: target{credlyUsername: "alice", owner: "acme", repo: "acme", file: "docs/team.md", branch: "main", section: "team"},
+		},
+		{
+			name:     "entry over defaults",
+			defaults: manifestEntry{CredlyUsername: "alice", Owner: "acme", Repo: "handbook", Branch: "docs"},
+			entry:    manifestEntry{CredlyUsername: "bob", Owner: "bob", Repo: "bob", Branch: "main", LocalFile: "bob.md"},
+			cdOpts:   cdOpts,
+			expected: target{credlyUsername: "bob", owner: "bob", repo: "bob", file: "README.md", branch: "main", localFile: "bob.md"},
+		},
+		{
+			name:     "repository flag",
+			entry:    manifestEntry{CredlyUsername: "alice", Owner: "acme"},
+			cdOpts:   &credlyBadgesOptions{repo: "handbook", file: "README.md", branch: "main"},
+			expected: target{credlyUsername: "alice", owner: "acme", repo: "handbook", file: "README.md", branch: "main"},
+		},
+		{
+			name:     "options",
+			defaults: manifestEntry{SectionOptions: readme.SectionOptions{Layout: "table", Columns: 3}},
+			entry:    manifestEntry{CredlyUsername: "alice", SectionOptions: readme.SectionOptions{Columns: 5}},
+			cdOpts:   cdOpts,
+			expected: target{
+				credlyUsername: "alice", owner: "flag-owner", repo: "flag-owner", file: "README.md", branch: "main",
+				options: readme.SectionOptions{Layout: "table", Columns: 5},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			m := &manifest{Defaults: tc.defaults}
+			if got := m.target(tc.entry, tc.cdOpts); !reflect.DeepEqual(got, tc.expected) {
+				t.Fatalf("expected %+v, got %+v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestReport(t *testing.T) {
+	notFound := fmt.Errorf("failed to fetch Credly user (alice) badges: %w", credly.ErrNotFound)
+	conflict := fmt.Errorf("update: %w", readme.ErrConflict)
+
+	tt := []struct {
+		name     string
+		results  []runResult
+		errs     []error
+		expected int
+		diff     string
+	}{
+		{
+			name:     "updated",
+			results:  []runResult{{status: statusUpdated}, {status: statusUnchanged}},
+			errs:     []error{nil, nil},
+			expected: 0,
+		},
+		{
+			name:     "would change",
+			results:  []runResult{{status: statusUnchanged}, {status: statusChanged, diff: "--- a\n"}, {status: statusChanged, diff: "--- b\n"}},
+			errs:     []error{nil, nil, nil},
+			expected: exitChanges,
+			diff:     "--- a\n--- b\n",
+		},
+		{
+			name:     "first failure",
+			results:  []runResult{{status: statusChanged, diff: "--- a\n"}, {}, {}},
+			errs:     []error{nil, notFound, conflict},
+			expected: exitNotFound,
+			diff:     "--- a\n",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			targets := make([]target, len(tc.results))
+			for i := range targets {
+				targets[i] = target{credlyUsername: "alice", localFile: fmt.Sprintf("%d.md", i)}
+			}
+
+			var diff bytes.Buffer
+			if code := report(&diff, targets, tc.results, tc.errs); code != tc.expected {
+				t.Fatalf("expected exit code %d, got %d", tc.expected, code)
+			}
+
+			if diff.String() != tc.diff {
+				t.Fatalf("expected diff %q, got %q", tc.diff, diff.String())
+			}
+		})
+	}
+}
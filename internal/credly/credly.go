@@ -2,20 +2,44 @@ package credly
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	"golang.org/x/net/html"
 )
 
 const credlyBaseURL = "https://www.credly.com/"
 
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrRateLimited  = errors.New("rate limited")
+)
+
+// Badge is a Credly badge earned by a user, as rendered in a README.
 type Badge struct {
-	ImageSrc string
-	Alt      string
+	ID           string
+	Name         string
+	ImageSrc     string
+	Alt          string
+	URL          string
+	Issuer       string
+	IssuedAt     time.Time
+	ExpiresAt    time.Time
+	Level        string
+	TypeCategory string
+	Cost         string
+	TimeToEarn   string
+	Description  string
+	Skills       []string
+	Evidence     []Evidence
 }
 
 type Credly struct {
@@ -42,8 +66,79 @@ func (c *Credly) WithBaseURL(baseURL string) *Credly {
 
 // FetchCredlyUserPage fetches the Credly user page for the provided username.
 func (c *Credly) FetchUserPage(ctx context.Context, username string) ([]byte, error) {
-	urlString := c.baseURL + "users/" + username + "/badges"
+	body, err := c.get(ctx, c.baseURL+"users/"+username+"/badges")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch Credly user (%s) page: %w", username, err)
+	}
+
+	return body, nil
+}
+
+// FetchBadges fetches all badges for the provided username from the Credly
+// badges.json endpoint, following the paging metadata until every page has
+// been fetched.
+func (c *Credly) FetchBadges(ctx context.Context, username string) ([]UserBadge, error) {
+	var badges []UserBadge
+
+	seen := make(map[string]bool)
+	pageURL := c.baseURL + "users/" + username + "/badges.json?page=1"
+
+	for pageURL != "" {
+		if seen[pageURL] {
+			return nil, fmt.Errorf("failed to fetch Credly user (%s) badges: page %s requested twice", username, pageURL)
+		}
+		seen[pageURL] = true
+
+		body, err := c.get(ctx, pageURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch Credly user (%s) badges: %w", username, err)
+		}
 
+		var resp BadgesResponse
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return nil, fmt.Errorf("failed to decode Credly user (%s) badges: %w", username, err)
+		}
+
+		badges = append(badges, resp.Data...)
+
+		pageURL, err = c.nextPageURL(pageURL, resp.Metadata)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return badges, nil
+}
+
+// nextPageURL returns the URL of the page following the current one, or an
+// empty string when there are no more pages.
+func (c *Credly) nextPageURL(current string, metadata Metadata) (string, error) {
+	currentURL, err := url.Parse(current)
+	if err != nil {
+		return "", err
+	}
+
+	if metadata.NextPageURL != "" {
+		next, err := url.Parse(metadata.NextPageURL)
+		if err != nil {
+			return "", err
+		}
+
+		return currentURL.ResolveReference(next).String(), nil
+	}
+
+	if metadata.CurrentPage == 0 || metadata.CurrentPage >= metadata.TotalPages {
+		return "", nil
+	}
+
+	query := currentURL.Query()
+	query.Set("page", strconv.Itoa(metadata.CurrentPage+1))
+	currentURL.RawQuery = query.Encode()
+
+	return currentURL.String(), nil
+}
+
+func (c *Credly) get(ctx context.Context, urlString string) ([]byte, error) {
 	parsedURL, err := url.Parse(urlString)
 	if err != nil {
 		return nil, err
@@ -62,8 +157,15 @@ func (c *Credly) FetchUserPage(ctx context.Context, username string) ([]byte, er
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("failed to fetch Credly user (%s) page: %s", username, resp.Status)
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, resp.Status)
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return nil, fmt.Errorf("%w: %s", ErrUnauthorized, resp.Status)
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return nil, fmt.Errorf("%w: %s", ErrRateLimited, resp.Status)
+	case resp.StatusCode >= 400:
+		return nil, errors.New(resp.Status)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -88,20 +190,7 @@ func ExtractBadges(htmlBody []byte) ([]Badge, error) {
 		if n.Type == html.ElementNode && n.Data == "div" {
 			for _, a := range n.Attr {
 				if a.Key == "class" && a.Val == "cr-standard-grid-item-content c-badge c-badge--medium" {
-					var badge Badge
-					for c := n.FirstChild; c != nil; c = c.NextSibling {
-						if c.Type == html.ElementNode && c.Data == "img" {
-							for _, a := range c.Attr {
-								if a.Key == "src" {
-									badge.ImageSrc = a.Val
-								}
-								if a.Key == "alt" {
-									badge.Alt = a.Val
-								}
-							}
-						}
-					}
-					badges = append(badges, badge)
+					badges = append(badges, extractBadge(n))
 					break
 				}
 			}
@@ -114,3 +203,150 @@ func ExtractBadges(htmlBody []byte) ([]Badge, error) {
 
 	return badges, nil
 }
+
+// extractBadge extracts a badge from its grid item content node, which is
+// wrapped in an anchor linking to the public badge page.
+func extractBadge(n *html.Node) Badge {
+	var badge Badge
+
+	if p := n.Parent; p != nil && p.Type == html.ElementNode && p.Data == "a" {
+		for _, a := range p.Attr {
+			if a.Key == "title" {
+				badge.Name = a.Val
+			}
+			if a.Key == "href" && strings.HasPrefix(a.Val, "/badges/") {
+				badge.ID = strings.TrimPrefix(a.Val, "/badges/")
+				badge.URL = BadgeURL(badge.ID)
+			}
+		}
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && c.Data == "img" {
+			for _, a := range c.Attr {
+				if a.Key == "src" {
+					badge.ImageSrc = a.Val
+				}
+				if a.Key == "alt" {
+					badge.Alt = a.Val
+				}
+			}
+		}
+	}
+
+	var f func(*html.Node)
+	f = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "div" {
+			for _, a := range n.Attr {
+				if a.Key != "class" {
+					continue
+				}
+				switch a.Val {
+				case "cr-standard-grid-item-content__title":
+					if badge.Name == "" {
+						badge.Name = textContent(n)
+					}
+				case "cr-standard-grid-item-content__subtitle":
+					badge.Issuer = textContent(n)
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			f(c)
+		}
+	}
+	f(n)
+
+	if badge.Alt == "" {
+		badge.Alt = badge.Name
+	}
+
+	return badge
+}
+
+func textContent(n *html.Node) string {
+	var sb strings.Builder
+
+	var f func(*html.Node)
+	f = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			sb.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			f(c)
+		}
+	}
+	f(n)
+
+	return strings.TrimSpace(sb.String())
+}
+
+// Expired reports whether the badge has expired at the provided time.
+func (b Badge) Expired(now time.Time) bool {
+	return !b.ExpiresAt.IsZero() && !now.Before(b.ExpiresAt)
+}
+
+// NewBadge creates a Badge from a badge described by the Credly JSON data.
+func NewBadge(ub UserBadge) Badge {
+	badge := Badge{
+		ID:           ub.ID,
+		Name:         ub.BadgeTemplate.Name,
+		ImageSrc:     ub.ImageURL,
+		Alt:          ub.BadgeTemplate.Name,
+		URL:          BadgeURL(ub.ID),
+		Issuer:       ub.Issuer.IssuerName(),
+		IssuedAt:     parseDate(ub.IssuedAtDate),
+		ExpiresAt:    parseDate(ub.ExpiresAtDate),
+		Level:        ub.BadgeTemplate.Level,
+		TypeCategory: ub.BadgeTemplate.TypeCategory,
+		Cost:         ub.BadgeTemplate.Cost,
+		TimeToEarn:   ub.BadgeTemplate.TimeToEarn,
+		Description:  ub.BadgeTemplate.Description,
+		Evidence:     ub.Evidence,
+	}
+
+	if badge.ImageSrc == "" {
+		badge.ImageSrc = ub.BadgeTemplate.ImageURL
+	}
+
+	if badge.Issuer == "" {
+		badge.Issuer = ub.BadgeTemplate.Issuer.IssuerName()
+	}
+
+	for _, skill := range ub.BadgeTemplate.Skills {
+		badge.Skills = append(badge.Skills, skill.Name)
+	}
+
+	return badge
+}
+
+// NewBadges creates Badges from the badges described by the Credly JSON data.
+func NewBadges(userBadges []UserBadge) []Badge {
+	badges := make([]Badge, 0, len(userBadges))
+	for _, ub := range userBadges {
+		badges = append(badges, NewBadge(ub))
+	}
+
+	return badges
+}
+
+// BadgeURL returns the public verification URL of the badge with the
+// provided id.
+func BadgeURL(id string) string {
+	if id == "" {
+		return ""
+	}
+
+	return credlyBaseURL + "badges/" + id
+}
+
+// parseDate parses a Credly date such as 2026-03-14, returning the zero time
+// if the date is empty or malformed.
+func parseDate(date string) time.Time {
+	t, err := time.Parse(time.DateOnly, date)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return t
+}
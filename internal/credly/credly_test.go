@@ -1,7 +1,13 @@
 package credly_test
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/mikejoh/go-credly/internal/credly"
 )
@@ -17,12 +23,20 @@ func TestExtractBadges(t *testing.T) {
 			html: credlyHTML,
 			expected: []credly.Badge{
 				{
+					ID:       "20f4aaea-770e-4e32-8cd0-f2720fb11d85",
+					Name:     "CKA: Certified Kubernetes Administrator",
 					ImageSrc: "https://images.credly.com/size/110x110/images/8b8ed108-e77d-4396-ac59-2504583b9d54/cka_from_cncfsite__281_29.png",
-					Alt:      "",
+					Alt:      "CKA: Certified Kubernetes Administrator",
+					URL:      "https://www.credly.com/badges/20f4aaea-770e-4e32-8cd0-f2720fb11d85",
+					Issuer:   "The Linux Foundation",
 				},
 				{
+					ID:       "062ae104-f532-43d0-b3bd-b6599dd03e2c",
+					Name:     "KCNA: Kubernetes and Cloud Native Associate",
 					ImageSrc: "https://images.credly.com/size/110x110/images/f28f1d88-428a-47f6-95b5-7da1dd6c1000/KCNA_badge.png",
-					Alt:      "",
+					Alt:      "KCNA: Kubernetes and Cloud Native Associate",
+					URL:      "https://www.credly.com/badges/062ae104-f532-43d0-b3bd-b6599dd03e2c",
+					Issuer:   "The Linux Foundation",
 				},
 			},
 		},
@@ -61,11 +75,388 @@ func TestExtractBadges(t *testing.T) {
 				if badge.Alt != tc.expected[i].Alt {
 					t.Fatalf("expected alt %s, got %s", tc.expected[i].Alt, badge.Alt)
 				}
+
+				if badge.ID != tc.expected[i].ID {
+					t.Fatalf("expected id %s, got %s", tc.expected[i].ID, badge.ID)
+				}
+
+				if badge.URL != tc.expected[i].URL {
+					t.Fatalf("expected url %s, got %s", tc.expected[i].URL, badge.URL)
+				}
+
+				if badge.Name != tc.expected[i].Name {
+					t.Fatalf("expected name %s, got %s", tc.expected[i].Name, badge.Name)
+				}
+
+				if badge.Issuer != tc.expected[i].Issuer {
+					t.Fatalf("expected issuer %s, got %s", tc.expected[i].Issuer, badge.Issuer)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractPrerenderData(t *testing.T) {
+	tt := []struct {
+		name     string
+		html     string
+		expected []credly.UserBadge
+		err      error
+	}{
+		{
+			name: "extract prerender data",
+			html: credlyHTML,
+			expected: []credly.UserBadge{
+				{
+					ID:            "20f4aaea-770e-4e32-8cd0-f2720fb11d85",
+					IssuedAtDate:  "2023-03-14",
+					ExpiresAtDate: "2026-03-14",
+					BadgeTemplate: credly.BadgeTemplate{
+						Name:         "CKA: Certified Kubernetes Administrator",
+						Level:        "Intermediate",
+						TypeCategory: "Certification",
+					},
+				},
+				{
+					ID:            "062ae104-f532-43d0-b3bd-b6599dd03e2c",
+					IssuedAtDate:  "2024-08-29",
+					ExpiresAtDate: "2026-08-30",
+					BadgeTemplate: credly.BadgeTemplate{
+						Name:         "KCNA: Kubernetes and Cloud Native Associate",
+						Level:        "Foundational",
+						TypeCategory: "Certification",
+					},
+				},
+			},
+		},
+
+		{
+			name: "no prerender data",
+			html: "<html></html>",
+			err:  credly.ErrPrerenderDataNotFound,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			resp, err := credly.ExtractPrerenderData([]byte(tc.html))
+			if tc.err != nil {
+				if !errors.Is(err, tc.err) {
+					t.Fatalf("expected error %v, got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if len(resp.Data) != len(tc.expected) {
+				t.Fatalf("expected %d badges, got %d", len(tc.expected), len(resp.Data))
+			}
+
+			if resp.Metadata.TotalPages != 1 || resp.Metadata.Per != 48 {
+				t.Fatalf("unexpected metadata %+v", resp.Metadata)
+			}
+
+			for i, badge := range resp.Data {
+				expected := tc.expected[i]
+				if badge.ID != expected.ID {
+					t.Fatalf("expected id %s, got %s", expected.ID, badge.ID)
+				}
+
+				if badge.IssuedAtDate != expected.IssuedAtDate || badge.ExpiresAtDate != expected.ExpiresAtDate {
+					t.Fatalf("expected dates %s-%s, got %s-%s", expected.IssuedAtDate, expected.ExpiresAtDate, badge.IssuedAtDate, badge.ExpiresAtDate)
+				}
+
+				if badge.BadgeTemplate.Name != expected.BadgeTemplate.Name {
+					t.Fatalf("expected name %s, got %s", expected.BadgeTemplate.Name, badge.BadgeTemplate.Name)
+				}
+
+				if badge.BadgeTemplate.Level != expected.BadgeTemplate.Level {
+					t.Fatalf("expected level %s, got %s", expected.BadgeTemplate.Level, badge.BadgeTemplate.Level)
+				}
+
+				if badge.BadgeTemplate.TypeCategory != expected.BadgeTemplate.TypeCategory {
+					t.Fatalf("expected type category %s, got %s", expected.BadgeTemplate.TypeCategory, badge.BadgeTemplate.TypeCategory)
+				}
+
+				if badge.Issuer.IssuerName() != "The Linux Foundation" {
+					t.Fatalf("expected issuer The Linux Foundation, got %s", badge.Issuer.IssuerName())
+				}
+
+				if len(badge.Evidence) != 1 {
+					t.Fatalf("expected 1 evidence, got %d", len(badge.Evidence))
+				}
 			}
 		})
 	}
 }
 
+func TestNewBadges(t *testing.T) {
+	t.Parallel()
+
+	resp, err := credly.ExtractPrerenderData([]byte(credlyHTML))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	badges := credly.NewBadges(resp.Data)
+	if len(badges) != 2 {
+		t.Fatalf("expected 2 badges, got %d", len(badges))
+	}
+
+	badge := badges[0]
+
+	expected := credly.Badge{
+		ID:           "20f4aaea-770e-4e32-8cd0-f2720fb11d85",
+		Name:         "CKA: Certified Kubernetes Administrator",
+		ImageSrc:     "https://images.credly.com/images/8b8ed108-e77d-4396-ac59-2504583b9d54/cka_from_cncfsite__281_29.png",
+		URL:          "https://www.credly.com/badges/20f4aaea-770e-4e32-8cd0-f2720fb11d85",
+		Issuer:       "The Linux Foundation",
+		IssuedAt:     time.Date(2023, time.March, 14, 0, 0, 0, 0, time.UTC),
+		ExpiresAt:    time.Date(2026, time.March, 14, 0, 0, 0, 0, time.UTC),
+		Level:        "Intermediate",
+		TypeCategory: "Certification",
+		Cost:         "Paid",
+		TimeToEarn:   "Months",
+	}
+
+	if badge.ID != expected.ID || badge.Name != expected.Name || badge.ImageSrc != expected.ImageSrc || badge.URL != expected.URL {
+		t.Fatalf("expected %+v, got %+v", expected, badge)
+	}
+
+	if badge.Issuer != expected.Issuer || badge.Level != expected.Level || badge.TypeCategory != expected.TypeCategory {
+		t.Fatalf("expected %+v, got %+v", expected, badge)
+	}
+
+	if badge.Cost != expected.Cost || badge.TimeToEarn != expected.TimeToEarn {
+		t.Fatalf("expected %+v, got %+v", expected, badge)
+	}
+
+	if !badge.IssuedAt.Equal(expected.IssuedAt) || !badge.ExpiresAt.Equal(expected.ExpiresAt) {
+		t.Fatalf("expected dates %s-%s, got %s-%s", expected.IssuedAt, expected.ExpiresAt, badge.IssuedAt, badge.ExpiresAt)
+	}
+
+	if len(badge.Skills) == 0 || badge.Skills[0] != "API objects" {
+		t.Fatalf("expected skills to start with API objects, got %v", badge.Skills)
+	}
+
+	if len(badge.Evidence) != 1 || badge.Evidence[0].Description != "LF-bv8xpen5hz" {
+		t.Fatalf("expected evidence LF-bv8xpen5hz, got %+v", badge.Evidence)
+	}
+}
+
+func TestResizeImageURL(t *testing.T) {
+	tt := []struct {
+		name     string
+		src      string
+		size     int
+		expected string
+	}{
+		{
+			name:     "resize sized url",
+			src:      "https://images.credly.com/size/110x110/images/f28f1d88-428a-47f6-95b5-7da1dd6c1000/KCNA_badge.png",
+			size:     64,
+			expected: "https://images.credly.com/size/64x64/images/f28f1d88-428a-47f6-95b5-7da1dd6c1000/KCNA_badge.png",
+		},
+
+		{
+			name:     "resize original url",
+			src:      "https://images.credly.com/images/f28f1d88-428a-47f6-95b5-7da1dd6c1000/KCNA_badge.png",
+			size:     340,
+			expected: "https://images.credly.com/size/340x340/images/f28f1d88-428a-47f6-95b5-7da1dd6c1000/KCNA_badge.png",
+		},
+
+		{
+			name:     "original size",
+			src:      "https://images.credly.com/size/110x110/images/f28f1d88-428a-47f6-95b5-7da1dd6c1000/KCNA_badge.png",
+			size:     credly.OriginalSize,
+			expected: "https://images.credly.com/images/f28f1d88-428a-47f6-95b5-7da1dd6c1000/KCNA_badge.png",
+		},
+
+		{
+			name:     "unchanged size",
+			src:      "https://images.credly.com/size/110x110/images/f28f1d88-428a-47f6-95b5-7da1dd6c1000/KCNA_badge.png",
+			size:     0,
+			expected: "https://images.credly.com/size/110x110/images/f28f1d88-428a-47f6-95b5-7da1dd6c1000/KCNA_badge.png",
+		},
+
+		{
+			name:     "other host",
+			src:      "https://example.com/size/110x110/images/badge.png",
+			size:     64,
+			expected: "https://example.com/size/110x110/images/badge.png",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := credly.ResizeImageURL(tc.src, tc.size); got != tc.expected {
+				t.Fatalf("expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestSortOrder(t *testing.T) {
+	badges := []credly.Badge{
+		{ID: "a", Name: "Beta", Level: "Advanced", IssuedAt: time.Date(2023, time.March, 14, 0, 0, 0, 0, time.UTC)},
+		{ID: "b", Name: "alpha", Level: "Foundational", IssuedAt: time.Date(2024, time.August, 29, 0, 0, 0, 0, time.UTC), ExpiresAt: time.Date(2026, time.August, 30, 0, 0, 0, 0, time.UTC)},
+		{ID: "c", Name: "Gamma", IssuedAt: time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC), ExpiresAt: time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	tt := []struct {
+		name     string
+		order    string
+		expected []string
+		err      bool
+	}{
+		{name: "fetched order", order: "", expected: []string{"a", "b", "c"}},
+		{name: "issued", order: "issued", expected: []string{"c", "a", "b"}},
+		{name: "issued descending", order: "-issued", expected: []string{"b", "a", "c"}},
+		{name: "expires", order: "expires", expected: []string{"c", "b", "a"}},
+		{name: "expires descending", order: "-expires", expected: []string{"b", "c", "a"}},
+		{name: "name", order: "name", expected: []string{"b", "a", "c"}},
+		{name: "level descending", order: "-level", expected: []string{"a", "b", "c"}},
+		{name: "unknown field", order: "popularity", err: true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			order, err := credly.ParseSortOrder(tc.order)
+			if tc.err {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			sorted := slices.Clone(badges)
+			order.Sort(sorted)
+
+			for i, badge := range sorted {
+				if badge.ID != tc.expected[i] {
+					t.Fatalf("expected order %v, got badge %s at %d", tc.expected, badge.ID, i)
+				}
+			}
+		})
+	}
+}
+
+func TestFilter(t *testing.T) {
+	badges := []credly.Badge{
+		{ID: "a", Name: "CKA: Certified Kubernetes Administrator", Issuer: "The Linux Foundation", TypeCategory: "Certification", Level: "Intermediate"},
+		{ID: "b", Name: "KCNA: Kubernetes and Cloud Native Associate", Issuer: "The Linux Foundation", TypeCategory: "Certification", Level: "Foundational"},
+		{ID: "c", Name: "Cloud Webinar Attendee", Issuer: "Acme", TypeCategory: "Learning"},
+	}
+
+	tt := []struct {
+		name     string
+		filter   credly.Filter
+		expected []string
+		err      bool
+	}{
+		{name: "no filter", expected: []string{"a", "b", "c"}},
+		{name: "include issuers", filter: credly.Filter{IncludeIssuers: []string{"the linux foundation"}}, expected: []string{"a", "b"}},
+		{name: "exclude issuers", filter: credly.Filter{ExcludeIssuers: []string{"The Linux Foundation"}}, expected: []string{"c"}},
+		{name: "include categories", filter: credly.Filter{IncludeCategories: []string{"Certification"}}, expected: []string{"a", "b"}},
+		{name: "exclude levels", filter: credly.Filter{ExcludeLevels: []string{"Foundational"}}, expected: []string{"a", "c"}},
+		{name: "include name", filter: credly.Filter{IncludeName: "^(CKA|KCNA):"}, expected: []string{"a", "b"}},
+		{name: "exclude name", filter: credly.Filter{ExcludeName: "(?i)webinar"}, expected: []string{"a", "b"}},
+		{name: "include ids", filter: credly.Filter{IncludeIDs: []string{"b", "c"}}, expected: []string{"b", "c"}},
+		{name: "exclude ids", filter: credly.Filter{ExcludeIDs: []string{"b"}}, expected: []string{"a", "c"}},
+		{name: "combined", filter: credly.Filter{IncludeCategories: []string{"Certification"}, ExcludeIDs: []string{"a"}}, expected: []string{"b"}},
+		{name: "invalid name pattern", filter: credly.Filter{IncludeName: "("}, err: true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			filtered, err := tc.filter.Apply(badges)
+			if tc.err {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if len(filtered) != len(tc.expected) {
+				t.Fatalf("expected %d badges, got %d", len(tc.expected), len(filtered))
+			}
+
+			for i, badge := range filtered {
+				if badge.ID != tc.expected[i] {
+					t.Fatalf("expected badges %v, got badge %s at %d", tc.expected, badge.ID, i)
+				}
+			}
+		})
+	}
+}
+
+func TestFetchBadges(t *testing.T) {
+	t.Parallel()
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/users/jane/badges.json" {
+			http.NotFound(w, r)
+			return
+		}
+
+		var page string
+		switch r.URL.Query().Get("page") {
+		case "1":
+			page = `{"data":[{"id":"1"},{"id":"2"}],"metadata":{"current_page":1,"total_pages":3,"per":2,"next_page_url":"` + srv.URL + `/users/jane/badges.json?page=2"}}`
+		case "2":
+			page = `{"data":[{"id":"3"},{"id":"4"}],"metadata":{"current_page":2,"total_pages":3,"per":2,"next_page_url":null}}`
+		case "3":
+			page = `{"data":[{"id":"5"}],"metadata":{"current_page":3,"total_pages":3,"per":2,"next_page_url":null}}`
+		default:
+			http.Error(w, "unexpected page", http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(page))
+	}))
+	defer srv.Close()
+
+	client := credly.NewClient().WithBaseURL(srv.URL + "/")
+
+	badges, err := client.FetchBadges(context.Background(), "jane")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := []string{"1", "2", "3", "4", "5"}
+	if len(badges) != len(expected) {
+		t.Fatalf("expected %d badges, got %d", len(expected), len(badges))
+	}
+
+	for i, badge := range badges {
+		if badge.ID != expected[i] {
+			t.Fatalf("expected id %s, got %s", expected[i], badge.ID)
+		}
+	}
+
+	if _, err := client.FetchBadges(context.Background(), "unknown"); !errors.Is(err, credly.ErrNotFound) {
+		t.Fatalf("expected %v for unknown user, got %v", credly.ErrNotFound, err)
+	}
+}
+
 // 2024-09-13
 var credlyHTML = `<!DOCTYPE html>
 <html lang='en'>
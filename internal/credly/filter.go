@@ -0,0 +1,100 @@
+package credly
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Filter selects which badges to render. Empty include lists match every
+// badge, and a badge matching any exclude list is dropped. Issuers,
+// categories and levels are matched case-insensitively, names by regular
+// expression.
+type Filter struct {
+	IncludeIssuers    []string `json:"include_issuers,omitempty"`
+	ExcludeIssuers    []string `json:"exclude_issuers,omitempty"`
+	IncludeCategories []string `json:"include_categories,omitempty"`
+	ExcludeCategories []string `json:"exclude_categories,omitempty"`
+	IncludeLevels     []string `json:"include_levels,omitempty"`
+	ExcludeLevels     []string `json:"exclude_levels,omitempty"`
+	IncludeName       string   `json:"include_name,omitempty"`
+	ExcludeName       string   `json:"exclude_name,omitempty"`
+	IncludeIDs        []string `json:"include_ids,omitempty"`
+	ExcludeIDs        []string `json:"exclude_ids,omitempty"`
+}
+
+// Apply returns the badges matching the filter, in their original order.
+func (f Filter) Apply(badges []Badge) ([]Badge, error) {
+	includeName, err := compilePattern(f.IncludeName)
+	if err != nil {
+		return nil, err
+	}
+
+	excludeName, err := compilePattern(f.ExcludeName)
+	if err != nil {
+		return nil, err
+	}
+
+	var filtered []Badge
+	for _, badge := range badges {
+		if !matchList(f.IncludeIssuers, f.ExcludeIssuers, badge.Issuer) {
+			continue
+		}
+
+		if !matchList(f.IncludeCategories, f.ExcludeCategories, badge.TypeCategory) {
+			continue
+		}
+
+		if !matchList(f.IncludeLevels, f.ExcludeLevels, badge.Level) {
+			continue
+		}
+
+		if includeName != nil && !includeName.MatchString(badge.Name) {
+			continue
+		}
+
+		if excludeName != nil && excludeName.MatchString(badge.Name) {
+			continue
+		}
+
+		if len(f.IncludeIDs) > 0 && !slices.Contains(f.IncludeIDs, badge.ID) {
+			continue
+		}
+
+		if slices.Contains(f.ExcludeIDs, badge.ID) {
+			continue
+		}
+
+		filtered = append(filtered, badge)
+	}
+
+	return filtered, nil
+}
+
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid badge name pattern %q: %w", pattern, err)
+	}
+
+	return re, nil
+}
+
+func matchList(include, exclude []string, value string) bool {
+	contains := func(list []string) bool {
+		return slices.ContainsFunc(list, func(s string) bool {
+			return strings.EqualFold(strings.TrimSpace(s), value)
+		})
+	}
+
+	if len(include) > 0 && !contains(include) {
+		return false
+	}
+
+	return !contains(exclude)
+}
@@ -0,0 +1,49 @@
+package credly
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// OriginalSize selects the original, unscaled badge image.
+const OriginalSize = -1
+
+// imageURLRe matches Credly image URLs, with or without a size segment, e.g.
+// https://images.credly.com/size/110x110/images/<id>/<file>.png
+var imageURLRe = regexp.MustCompile(`^(https?://images\.credly\.com/)(?:size/\d+x\d+/)?(images/.+)$`)
+
+// ResizeImageURL rewrites a Credly image URL to the provided size in pixels,
+// or to the original image for OriginalSize. Other URLs, and a size of zero,
+// leave the URL unchanged.
+func ResizeImageURL(src string, size int) string {
+	if size == 0 {
+		return src
+	}
+
+	m := imageURLRe.FindStringSubmatch(src)
+	if m == nil {
+		return src
+	}
+
+	if size == OriginalSize {
+		return m[1] + m[2]
+	}
+
+	return fmt.Sprintf("%ssize/%dx%d/%s", m[1], size, size, m[2])
+}
+
+// ParseImageSize parses an image size given either in pixels or as
+// "original".
+func ParseImageSize(s string) (int, error) {
+	if s == "original" {
+		return OriginalSize, nil
+	}
+
+	size, err := strconv.Atoi(s)
+	if err != nil || size <= 0 {
+		return 0, fmt.Errorf("invalid image size %q, expected a number of pixels or original", s)
+	}
+
+	return size, nil
+}
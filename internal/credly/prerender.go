@@ -0,0 +1,148 @@
+package credly
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var ErrPrerenderDataNotFound = errors.New("prerender badge data not found")
+
+// prerenderBadgesRe matches the assignment of the users badge list in the
+// inline prerender script, e.g.:
+//
+//	prerenderData[{"a":"/users/<username>/badges"}.a] =
+//	  JSON.parse({"a":"{\"data\":[...]}"}.a);
+var prerenderBadgesRe = regexp.MustCompile(`prerenderData\[\{"a":"/users/[^"]+/badges"\}\.a\]\s*=\s*JSON\.parse\(`)
+
+// BadgesResponse is the badge list payload Credly embeds in the user page,
+// one page of the users badges together with the paging metadata.
+type BadgesResponse struct {
+	Data     []UserBadge `json:"data"`
+	Metadata Metadata    `json:"metadata"`
+}
+
+type Metadata struct {
+	Count           int    `json:"count"`
+	CurrentPage     int    `json:"current_page"`
+	TotalCount      int    `json:"total_count"`
+	TotalPages      int    `json:"total_pages"`
+	Per             int    `json:"per"`
+	PreviousPageURL string `json:"previous_page_url"`
+	NextPageURL     string `json:"next_page_url"`
+}
+
+// UserBadge is a badge issued to a user as described by Credly.
+type UserBadge struct {
+	ID            string        `json:"id"`
+	IssuedAtDate  string        `json:"issued_at_date"`
+	ExpiresAtDate string        `json:"expires_at_date"`
+	IssuedTo      string        `json:"issued_to"`
+	Locale        string        `json:"locale"`
+	Public        bool          `json:"public"`
+	State         string        `json:"state"`
+	AcceptedAt    time.Time     `json:"accepted_at"`
+	ExpiresAt     time.Time     `json:"expires_at"`
+	IssuedAt      time.Time     `json:"issued_at"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+	EarnerPath    string        `json:"earner_path"`
+	Issuer        Issuer        `json:"issuer"`
+	BadgeTemplate BadgeTemplate `json:"badge_template"`
+	ImageURL      string        `json:"image_url"`
+	Evidence      []Evidence    `json:"evidence"`
+}
+
+type Issuer struct {
+	Summary  string         `json:"summary"`
+	Entities []IssuerEntity `json:"entities"`
+}
+
+type IssuerEntity struct {
+	Label   string `json:"label"`
+	Primary bool   `json:"primary"`
+	Entity  Entity `json:"entity"`
+}
+
+type Entity struct {
+	Type      string `json:"type"`
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	URL       string `json:"url"`
+	VanityURL string `json:"vanity_url"`
+	Verified  bool   `json:"verified"`
+}
+
+type BadgeTemplate struct {
+	ID           string  `json:"id"`
+	Name         string  `json:"name"`
+	Description  string  `json:"description"`
+	Level        string  `json:"level"`
+	TimeToEarn   string  `json:"time_to_earn"`
+	Cost         string  `json:"cost"`
+	TypeCategory string  `json:"type_category"`
+	ImageURL     string  `json:"image_url"`
+	URL          string  `json:"url"`
+	VanitySlug   string  `json:"vanity_slug"`
+	Issuer       Issuer  `json:"issuer"`
+	Skills       []Skill `json:"skills"`
+}
+
+type Skill struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	VanitySlug string `json:"vanity_slug"`
+}
+
+type Evidence struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	URL         string `json:"url"`
+}
+
+// IssuerName returns the name of the primary issuing entity, falling back to
+// the first listed entity.
+func (i Issuer) IssuerName() string {
+	for _, e := range i.Entities {
+		if e.Primary {
+			return e.Entity.Name
+		}
+	}
+
+	if len(i.Entities) > 0 {
+		return i.Entities[0].Entity.Name
+	}
+
+	return ""
+}
+
+// ExtractPrerenderData extracts the badge data Credly embeds as prerender data
+// in the provided HTML body.
+func ExtractPrerenderData(htmlBody []byte) (*BadgesResponse, error) {
+	loc := prerenderBadgesRe.FindIndex(htmlBody)
+	if loc == nil {
+		return nil, ErrPrerenderDataNotFound
+	}
+
+	// The argument to JSON.parse is an object literal wrapping the payload as
+	// a string, which itself is valid JSON.
+	var wrapper struct {
+		A string `json:"a"`
+	}
+
+	dec := json.NewDecoder(strings.NewReader(string(htmlBody[loc[1]:])))
+	if err := dec.Decode(&wrapper); err != nil {
+		return nil, fmt.Errorf("failed to decode prerender data: %w", err)
+	}
+
+	var resp BadgesResponse
+	if err := json.Unmarshal([]byte(wrapper.A), &resp); err != nil {
+		return nil, fmt.Errorf("failed to decode prerender badge data: %w", err)
+	}
+
+	return &resp, nil
+}
@@ -0,0 +1,115 @@
+package credly
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Fields badges can be sorted by.
+const (
+	SortIssued  = "issued"
+	SortExpires = "expires"
+	SortName    = "name"
+	SortIssuer  = "issuer"
+	SortLevel   = "level"
+)
+
+// levels orders the Credly badge levels from lowest to highest.
+var levels = map[string]int{
+	"foundational": 1,
+	"intermediate": 2,
+	"advanced":     3,
+}
+
+// SortOrder describes how to sort badges. The zero value keeps the order the
+// badges were fetched in.
+type SortOrder struct {
+	Field      string
+	Descending bool
+}
+
+// ParseSortOrder parses a sort order such as "issued" or "-issued", where a
+// leading - sorts in descending order. An empty string keeps the order the
+// badges were fetched in.
+func ParseSortOrder(s string) (SortOrder, error) {
+	var order SortOrder
+
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return order, nil
+	}
+
+	if strings.HasPrefix(s, "-") {
+		order.Descending = true
+		s = s[1:]
+	}
+
+	switch s {
+	case SortIssued, SortExpires, SortName, SortIssuer, SortLevel:
+		order.Field = s
+	default:
+		return SortOrder{}, fmt.Errorf("unknown sort field %q, expected one of %s, %s, %s, %s or %s", s, SortIssued, SortExpires, SortName, SortIssuer, SortLevel)
+	}
+
+	return order, nil
+}
+
+// Sort sorts the badges in place. Badges missing the sorted field, such as
+// badges that never expire, are placed last in both directions.
+func (o SortOrder) Sort(badges []Badge) {
+	if o.Field == "" {
+		return
+	}
+
+	slices.SortStableFunc(badges, func(a, b Badge) int {
+		aMissing, bMissing := o.missing(a), o.missing(b)
+		switch {
+		case aMissing && bMissing:
+			return 0
+		case aMissing:
+			return 1
+		case bMissing:
+			return -1
+		}
+
+		c := o.compare(a, b)
+		if o.Descending {
+			return -c
+		}
+		return c
+	})
+}
+
+func (o SortOrder) missing(b Badge) bool {
+	switch o.Field {
+	case SortIssued:
+		return b.IssuedAt.IsZero()
+	case SortExpires:
+		return b.ExpiresAt.IsZero()
+	case SortName:
+		return b.Name == ""
+	case SortIssuer:
+		return b.Issuer == ""
+	case SortLevel:
+		return levels[strings.ToLower(b.Level)] == 0
+	}
+	return false
+}
+
+func (o SortOrder) compare(a, b Badge) int {
+	switch o.Field {
+	case SortIssued:
+		return a.IssuedAt.Compare(b.IssuedAt)
+	case SortExpires:
+		return a.ExpiresAt.Compare(b.ExpiresAt)
+	case SortName:
+		return cmp.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	case SortIssuer:
+		return cmp.Compare(strings.ToLower(a.Issuer), strings.ToLower(b.Issuer))
+	case SortLevel:
+		return cmp.Compare(levels[strings.ToLower(a.Level)], levels[strings.ToLower(b.Level)])
+	}
+	return 0
+}
@@ -0,0 +1,144 @@
+package readme
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/mikejoh/go-credly/internal/credly"
+)
+
+// badgeURLRe matches the URL of a Credly badge, capturing the badge id.
+var badgeURLRe = regexp.MustCompile(`https://www\.credly\.com/badges/([0-9A-Za-z-]+)`)
+
+// Changes are the names of the badges added to and removed from the readme
+// by an update.
+type Changes struct {
+	Added   []string
+	Removed []string
+}
+
+// BadgeChanges compares the badges in the content of the badge sections
+// before and after an update. A badge of badges is found by the URL of its
+// verification page, or by its whole rendered name if neither content links
+// it, e.g. for templates rendering names only. Other badges are found by
+// their URL, and named from the markup they were rendered with, falling back
+// to the URL.
+func BadgeChanges(before, after string, badges []credly.Badge) Changes {
+	var changes Changes
+
+	beforeIDs, afterIDs := idSet(badgeIDs(before)), idSet(badgeIDs(after))
+
+	known := make(map[string]bool)
+	for _, b := range badges {
+		known[b.ID] = true
+
+		inBefore, inAfter := beforeIDs[b.ID], afterIDs[b.ID]
+		if !inBefore && !inAfter && b.Name != "" {
+			inBefore, inAfter = namesBadge(before, b.Name), namesBadge(after, b.Name)
+		}
+
+		switch {
+		case inAfter && !inBefore:
+			changes.Added = append(changes.Added, b.Name)
+		case inBefore && !inAfter:
+			changes.Removed = append(changes.Removed, b.Name)
+		}
+	}
+
+	for _, id := range badgeIDs(before) {
+		if !known[id] && !afterIDs[id] {
+			changes.Removed = append(changes.Removed, renderedName(before, id))
+		}
+	}
+
+	return changes
+}
+
+// namesBadge reports whether the content renders the name as a whole, e.g.
+// as a line, a table cell, the alt text of an image or the text of a link,
+// rather than as part of a longer name.
+func namesBadge(content, name string) bool {
+	for _, n := range []string{name, html.EscapeString(name)} {
+		re := regexp.MustCompile(`(?m)(?:^(?:\s*[-*]\s+)?|[>"\[|]\s*)` + regexp.QuoteMeta(n) + `\s*(?:$|[<"\]|,]|\s\()`)
+		if re.MatchString(content) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func idSet(ids []string) map[string]bool {
+	set := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
+
+// Empty reports whether no badges were added or removed.
+func (c Changes) Empty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0
+}
+
+// Summary returns a Markdown summary of the changes, e.g. for the
+// description of a pull request.
+func (c Changes) Summary() string {
+	var sb strings.Builder
+
+	sb.WriteString("Updates the Credly badges in the readme.\n")
+
+	list := func(heading string, names []string) {
+		if len(names) == 0 {
+			return
+		}
+		fmt.Fprintf(&sb, "\n**%s (%d)**\n\n", heading, len(names))
+		for _, name := range names {
+			fmt.Fprintf(&sb, "- %s\n", name)
+		}
+	}
+	list("Added", c.Added)
+	list("Removed", c.Removed)
+
+	if c.Empty() {
+		sb.WriteString("\nNo badges were added or removed, only how they are rendered changed.\n")
+	}
+
+	return sb.String()
+}
+
+// badgeIDs returns the ids of the badges linked in the readme, in order of
+// appearance.
+func badgeIDs(readme string) []string {
+	var ids []string
+
+	seen := make(map[string]bool)
+	for _, m := range badgeURLRe.FindAllStringSubmatch(readme, -1) {
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			ids = append(ids, m[1])
+		}
+	}
+
+	return ids
+}
+
+// renderedName returns the name of the badge as rendered by the built-in
+// layouts, either as the alt text of a linked image or as the text of a
+// Markdown link.
+func renderedName(readme, id string) string {
+	badgeURL := regexp.QuoteMeta(credly.BadgeURL(id))
+
+	for _, re := range []*regexp.Regexp{
+		regexp.MustCompile(`href="` + badgeURL + `"><img [^>]*alt="([^"]*)"`),
+		regexp.MustCompile(`\[([^\]]+)\]\(` + badgeURL + `\)`),
+	} {
+		if m := re.FindStringSubmatch(readme); m != nil && m[1] != "" {
+			return html.UnescapeString(m[1])
+		}
+	}
+
+	return credly.BadgeURL(id)
+}
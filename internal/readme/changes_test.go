@@ -0,0 +1,114 @@
+package readme_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mikejoh/go-credly/internal/credly"
+	"github.com/mikejoh/go-credly/internal/readme"
+)
+
+func TestBadgeChanges(t *testing.T) {
+	t.Parallel()
+
+	images, err := readme.DefaultRenderer().Render(testBadges[:1])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := readme.NewLayoutRenderer(readme.LayoutList)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listed, err := list.Render(testBadges[1:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cka := credly.Badge{ID: "cka-2", Name: "CKA", URL: credly.BadgeURL("cka-2")}
+	ckad := credly.Badge{ID: "ckad-1", Name: "CKAD: Certified Kubernetes Application Developer"}
+	renewed := testBadges[0]
+	renewed.ID, renewed.URL = "cka-renewed", credly.BadgeURL("cka-renewed")
+
+	renewedListed, err := list.Render([]credly.Badge{renewed})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tt := []struct {
+		name     string
+		before   string
+		after    string
+		badges   []credly.Badge
+		expected readme.Changes
+	}{
+		{
+			name:     "added",
+			before:   "",
+			after:    images + listed,
+			expected: readme.Changes{Added: []string{"CKA: Certified Kubernetes Administrator", "KCNA: Kubernetes and Cloud Native Associate"}},
+		},
+		{
+			name:     "removed image",
+			before:   images + listed,
+			after:    listed,
+			expected: readme.Changes{Removed: []string{"CKA: Certified Kubernetes Administrator"}},
+		},
+		{
+			name:     "removed list item",
+			before:   listed,
+			after:    "",
+			expected: readme.Changes{Removed: []string{"KCNA: Kubernetes and Cloud Native Associate"}},
+		},
+		{
+			name:     "unknown markup",
+			before:   "https://www.credly.com/badges/abc-123",
+			after:    "",
+			expected: readme.Changes{Removed: []string{"https://www.credly.com/badges/abc-123"}},
+		},
+		{
+			name:     "name next to a longer name",
+			before:   ckad.Name + "\n",
+			after:    ckad.Name + "\n" + cka.Name + "\n",
+			badges:   []credly.Badge{ckad, {Name: cka.Name}},
+			expected: readme.Changes{Added: []string{"CKA"}},
+		},
+		{
+			name:     "unrendered name prefixing a rendered name",
+			before:   "| " + testBadges[0].Name + " |\n",
+			after:    "",
+			badges:   []credly.Badge{{Name: "CKA"}, {Name: testBadges[0].Name}},
+			expected: readme.Changes{Removed: []string{"CKA: Certified Kubernetes Administrator"}},
+		},
+		{
+			name:     "renewed",
+			before:   listed + images,
+			after:    listed + renewedListed,
+			badges:   []credly.Badge{testBadges[1], renewed},
+			expected: readme.Changes{Added: []string{"CKA: Certified Kubernetes Administrator"}, Removed: []string{"CKA: Certified Kubernetes Administrator"}},
+		},
+		{
+			name:     "unchanged",
+			before:   images,
+			after:    images,
+			expected: readme.Changes{},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			badges := tc.badges
+			if badges == nil {
+				badges = testBadges
+			}
+
+			changes := readme.BadgeChanges(tc.before, tc.after, badges)
+			if !reflect.DeepEqual(changes, tc.expected) {
+				t.Fatalf("expected %+v, got %+v", tc.expected, changes)
+			}
+		})
+	}
+}
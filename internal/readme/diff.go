@@ -0,0 +1,159 @@
+package readme
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines around each hunk of a diff.
+const diffContext = 3
+
+// diffLine is a line of a diff, kind is one of ' ', '-' or '+'.
+type diffLine struct {
+	kind byte
+	text string
+}
+
+// UnifiedDiff returns the unified diff between two versions of a file, or an
+// empty string if they are equal.
+func UnifiedDiff(fromName, toName, before, after string) string {
+	if before == after {
+		return ""
+	}
+
+	lines := diffLines(splitLines(before), splitLines(after))
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", fromName, toName)
+
+	for start := 0; start < len(lines); {
+		// Find the next change and the end of its hunk, changes separated by
+		// less than twice the context are merged into the same hunk.
+		first := start
+		for first < len(lines) && lines[first].kind == ' ' {
+			first++
+		}
+		if first == len(lines) {
+			break
+		}
+
+		last, unchanged := first, 0
+		for i := first; i < len(lines) && unchanged <= 2*diffContext; i++ {
+			if lines[i].kind == ' ' {
+				unchanged++
+				continue
+			}
+			last, unchanged = i, 0
+		}
+
+		from, to := max(first-diffContext, 0), min(last+diffContext+1, len(lines))
+		writeHunk(&sb, lines, from, to)
+		start = to
+	}
+
+	return sb.String()
+}
+
+// writeHunk writes the lines in [from, to) as a hunk with its header.
+func writeHunk(sb *strings.Builder, lines []diffLine, from, to int) {
+	oldStart, newStart := 1, 1
+	for _, l := range lines[:from] {
+		if l.kind != '+' {
+			oldStart++
+		}
+		if l.kind != '-' {
+			newStart++
+		}
+	}
+
+	var oldCount, newCount int
+	for _, l := range lines[from:to] {
+		if l.kind != '+' {
+			oldCount++
+		}
+		if l.kind != '-' {
+			newCount++
+		}
+	}
+
+	// An empty range starts at the line before it.
+	if oldCount == 0 {
+		oldStart--
+	}
+	if newCount == 0 {
+		newStart--
+	}
+
+	fmt.Fprintf(sb, "@@ -%s +%s @@\n", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount))
+	for _, l := range lines[from:to] {
+		sb.WriteByte(l.kind)
+		sb.WriteString(l.text)
+		if !strings.HasSuffix(l.text, "\n") {
+			sb.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+func hunkRange(start, count int) string {
+	if count == 1 {
+		return fmt.Sprint(start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// splitLines splits the text into lines, keeping the line endings.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// diffLines returns the shortest edit from a to b, based on their longest
+// common subsequence of lines.
+func diffLines(a, b []string) []diffLine {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var lines []diffLine
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, diffLine{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, diffLine{'-', a[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, diffLine{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, diffLine{'+', b[j]})
+	}
+
+	return lines
+}
@@ -0,0 +1,58 @@
+package readme_test
+
+import (
+	"testing"
+
+	"github.com/mikejoh/go-credly/internal/readme"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tt := []struct {
+		name     string
+		before   string
+		after    string
+		expected string
+	}{
+		{
+			name:     "equal",
+			before:   "a\nb\n",
+			after:    "a\nb\n",
+			expected: "",
+		},
+		{
+			name:     "changed line",
+			before:   "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			after:    "1\n2\n3\n4\nfive\n6\n7\n8\n9\n",
+			expected: "--- a/README.md\n+++ b/README.md\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+		},
+		{
+			name:     "separate hunks",
+			before:   "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			after:    "one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n",
+			expected: "--- a/README.md\n+++ b/README.md\n@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n@@ -10,3 +10,4 @@\n 10\n 11\n 12\n+13\n",
+		},
+		{
+			name:     "added to empty file",
+			before:   "",
+			after:    "a\n",
+			expected: "--- a/README.md\n+++ b/README.md\n@@ -0,0 +1 @@\n+a\n",
+		},
+		{
+			name:     "no newline at end of file",
+			before:   "a\nb",
+			after:    "a\nc",
+			expected: "--- a/README.md\n+++ b/README.md\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+c\n\\ No newline at end of file\n",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			diff := readme.UnifiedDiff("a/README.md", "b/README.md", tc.before, tc.after)
+			if diff != tc.expected {
+				t.Fatalf("expected %q, got %q", tc.expected, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package readme
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// GiteaStorage stores the readme in a repository on a Gitea or Forgejo
+// instance, using the repository contents API.
+type GiteaStorage struct {
+	baseURL  string
+	client   http.Client
+	token    string
+	owner    string
+	repo     string
+	fileName string
+	branch   string
+}
+
+// NewGiteaStorage returns a storage for the repository on the instance at
+// baseURL, e.g. https://gitea.example.com.
+func NewGiteaStorage(baseURL, owner, repo string) *GiteaStorage {
+	return &GiteaStorage{
+		baseURL:  baseURL,
+		client:   http.Client{},
+		owner:    owner,
+		repo:     repo,
+		fileName: "README.md",
+		branch:   "main",
+	}
+}
+
+func (gs *GiteaStorage) WithHTTPClient(client http.Client) *GiteaStorage {
+	gs.client = client
+	return gs
+}
+
+func (gs *GiteaStorage) WithToken(token string) *GiteaStorage {
+	gs.token = token
+	return gs
+}
+
+func (gs *GiteaStorage) WithFileName(filename string) *GiteaStorage {
+	gs.fileName = filename
+	return gs
+}
+
+func (gs *GiteaStorage) WithBranch(branch string) *GiteaStorage {
+	gs.branch = branch
+	return gs
+}
+
+// giteaContents is a file returned by the contents API.
+type giteaContents struct {
+	Encoding string `json:"encoding"`
+	Content  string `json:"content"`
+	SHA      string `json:"sha"`
+}
+
+// giteaUpdate is the body of a contents API update request.
+type giteaUpdate struct {
+	Branch    string    `json:"branch"`
+	Content   string    `json:"content"`
+	Message   string    `json:"message"`
+	SHA       string    `json:"sha"`
+	Author    *Identity `json:"author,omitempty"`
+	Committer *Identity `json:"committer,omitempty"`
+}
+
+// Fetch fetches the readme from the branch, its revision is the blob SHA of
+// the file.
+func (gs *GiteaStorage) Fetch(ctx context.Context) (File, error) {
+	file, err := gs.getContents(ctx, gs.fileName)
+	if err != nil {
+		return File{}, err
+	}
+
+	content, err := decodeContent(file.Encoding, file.Content)
+	if err != nil {
+		return File{}, err
+	}
+
+	return File{Content: content, Revision: file.SHA}, nil
+}
+
+// FetchFile fetches the content of another file in the repository, such as
+// a badge template.
+func (gs *GiteaStorage) FetchFile(ctx context.Context, path string) (string, error) {
+	file, err := gs.getContents(ctx, path)
+	if err != nil {
+		return "", err
+	}
+
+	return decodeContent(file.Encoding, file.Content)
+}
+
+// Write commits the readme to the branch. The instance rejects the commit
+// with ErrConflict if the file no longer has the SHA of the commit revision.
+func (gs *GiteaStorage) Write(ctx context.Context, commit Commit) (string, error) {
+	body, err := json.Marshal(giteaUpdate{
+		Branch:    gs.branch,
+		Content:   base64.StdEncoding.EncodeToString([]byte(commit.Content)),
+		Message:   commit.Message,
+		SHA:       commit.Revision,
+		Author:    commit.Author,
+		Committer: commit.Committer,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := gs.do(ctx, http.MethodPut, gs.contentsURL(gs.fileName), bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var updated struct {
+		Content giteaContents `json:"content"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&updated); err != nil {
+		return "", fmt.Errorf("failed to decode Gitea update of %s: %w", gs.fileName, err)
+	}
+
+	return updated.Content.SHA, nil
+}
+
+func (gs *GiteaStorage) Filename() string {
+	return gs.fileName
+}
+
+func (gs *GiteaStorage) getContents(ctx context.Context, path string) (*giteaContents, error) {
+	resp, err := gs.do(ctx, http.MethodGet, gs.contentsURL(path)+"?ref="+url.QueryEscape(gs.branch), http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var file giteaContents
+	if err := json.NewDecoder(resp.Body).Decode(&file); err != nil {
+		return nil, fmt.Errorf("failed to decode Gitea file %s: %w", path, err)
+	}
+
+	return &file, nil
+}
+
+// contentsURL returns the contents API URL of the file in the repository.
+func (gs *GiteaStorage) contentsURL(path string) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+
+	return strings.TrimSuffix(gs.baseURL, "/") + "/api/v1/repos/" + url.PathEscape(gs.owner) + "/" + url.PathEscape(gs.repo) + "/contents/" + strings.Join(segments, "/")
+}
+
+// do sends a request to the Gitea API. Depending on the version, an update
+// based on an outdated SHA is answered with 409 or with 422 and a message.
+func (gs *GiteaStorage) do(ctx context.Context, method, urlString string, body io.Reader) (*http.Response, error) {
+	api := forgeAPI{
+		client:     &gs.client,
+		authHeader: "Authorization",
+		conflict: func(code int, message string) bool {
+			return code == http.StatusConflict || strings.Contains(strings.ToLower(message), "sha does not match")
+		},
+	}
+	if gs.token != "" {
+		api.authValue = "token " + gs.token
+	}
+
+	return api.do(ctx, method, urlString, body)
+}
@@ -0,0 +1,129 @@
+package readme_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/mikejoh/go-credly/internal/readme"
+)
+
+// giteaServer is a stand-in for the Gitea contents API holding a single
+// README.md on the main branch of the mikejoh/mikejoh repository.
+type giteaServer struct {
+	mu      sync.Mutex
+	content string
+	blob    int
+}
+
+func (s *giteaServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "token secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	if r.URL.Path != "/api/v1/repos/mikejoh/mikejoh/contents/README.md" {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message":"object does not exist"}`))
+		return
+	}
+
+	sha := "blob-" + strconv.Itoa(s.blob)
+
+	switch r.Method {
+	case http.MethodGet:
+		if r.URL.Query().Get("ref") != "main" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"encoding": "base64",
+			"content":  base64.StdEncoding.EncodeToString([]byte(s.content)),
+			"sha":      sha,
+		})
+	case http.MethodPut:
+		var update struct {
+			Branch  string `json:"branch"`
+			Content string `json:"content"`
+			Message string `json:"message"`
+			SHA     string `json:"sha"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&update); err != nil || update.Branch != "main" || update.Message == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if update.SHA != sha {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = w.Write([]byte(`{"message":"sha does not match [given: ` + update.SHA + `, expected: ` + sha + `]"}`))
+			return
+		}
+		content, err := base64.StdEncoding.DecodeString(update.Content)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.content = string(content)
+		s.blob++
+		_ = json.NewEncoder(w).Encode(map[string]map[string]string{
+			"content": {"sha": "blob-" + strconv.Itoa(s.blob)},
+		})
+	}
+}
+
+func TestGiteaStorage(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	stub := &giteaServer{content: "# Hi\n<!--START_BADGES:badges layout=list-->\n<!--END_BADGES:badges-->\n", blob: 1}
+	srv := httptest.NewServer(stub)
+	defer srv.Close()
+
+	newStorage := func() *readme.GiteaStorage {
+		return readme.NewGiteaStorage(srv.URL, "mikejoh", "mikejoh").WithToken("secret")
+	}
+
+	r := readme.New(newStorage())
+	if err := r.Fetch(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	stale := readme.New(newStorage()).WithRetries(0)
+	if err := stale.Fetch(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	sections := []readme.Section{{Name: readme.DefaultSection}}
+	if err := r.WriteSections(testBadges[:1], sections); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if err := r.Update(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := "# Hi\n<!--START_BADGES:badges layout=list-->\n- [CKA: Certified Kubernetes Administrator](https://www.credly.com/badges/20f4aaea-770e-4e32-8cd0-f2720fb11d85), The Linux Foundation (2023-03-14)\n<!--END_BADGES:badges-->\n"
+	if stub.content != expected {
+		t.Fatalf("expected %q, got %q", expected, stub.content)
+	}
+
+	if err := stale.WriteSections(testBadges, sections); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if err := stale.Update(ctx); !errors.Is(err, readme.ErrConflict) {
+		t.Fatalf("expected %v, got %v", readme.ErrConflict, err)
+	}
+
+	if _, err := readme.New(newStorage()).FetchFile(ctx, ".github/badges.tmpl"); !errors.Is(err, readme.ErrNotFound) {
+		t.Fatalf("expected %v, got %v", readme.ErrNotFound, err)
+	}
+}
@@ -0,0 +1,374 @@
+package readme
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	gh "github.com/google/go-github/v64/github"
+)
+
+// GitHubStorage stores the readme in a GitHub repository, using the
+// repository contents API. The readme is README.md on the main branch unless
+// configured otherwise, e.g. docs/people/alice.md in an organization
+// repository.
+type GitHubStorage struct {
+	githubClient *gh.Client
+	fileName     string
+	branch       string
+	prBranch     string
+	signer       gh.MessageSigner
+	verified     bool
+	repo         string
+	owner        string
+}
+
+func NewGitHubStorage(owner, repo string) *GitHubStorage {
+	return &GitHubStorage{
+		githubClient: gh.NewClient(nil),
+		fileName:     "README.md",
+		branch:       "main",
+		repo:         repo,
+		owner:        owner,
+	}
+}
+
+func (gs *GitHubStorage) WithGitHubClient(client *gh.Client) *GitHubStorage {
+	gs.githubClient = client
+	return gs
+}
+
+func (gs *GitHubStorage) WithFileName(filename string) *GitHubStorage {
+	gs.fileName = filename
+	return gs
+}
+
+func (gs *GitHubStorage) WithBranch(branch string) *GitHubStorage {
+	gs.branch = branch
+	return gs
+}
+
+// WithPullRequest makes Write commit the readme to the provided feature
+// branch and open a pull request against the branch, instead of committing
+// to the branch directly.
+func (gs *GitHubStorage) WithPullRequest(branch string) *GitHubStorage {
+	gs.prBranch = branch
+	return gs
+}
+
+// WithSigner makes Write create commits signed by the signer, through the
+// Git Data API. GitHub shows them as verified when the signing key belongs to
+// the commit author.
+func (gs *GitHubStorage) WithSigner(signer gh.MessageSigner) *GitHubStorage {
+	gs.signer = signer
+	return gs
+}
+
+// WithVerifiedCommits leaves the author and committer of commits unset, so
+// that GitHub signs them as the owner of the token. Commits made with a
+// GitHub App installation token, including the token of GitHub Actions, are
+// then shown as verified.
+func (gs *GitHubStorage) WithVerifiedCommits() *GitHubStorage {
+	gs.verified = true
+	return gs
+}
+
+// Fetch fetches the readme from the branch, its revision is the blob SHA of
+// the file.
+func (gs *GitHubStorage) Fetch(ctx context.Context) (File, error) {
+	content, _, _, err := gs.githubClient.Repositories.GetContents(ctx, gs.owner, gs.repo, gs.fileName, &gh.RepositoryContentGetOptions{Ref: gs.branch})
+	if err != nil {
+		return File{}, githubError(err)
+	}
+
+	readmeString, err := content.GetContent()
+	if err != nil {
+		return File{}, err
+	}
+
+	return File{Content: readmeString, Revision: content.GetSHA()}, nil
+}
+
+// FetchFile fetches the content of another file on the branch, such as a
+// badge template.
+func (gs *GitHubStorage) FetchFile(ctx context.Context, path string) (string, error) {
+	content, _, _, err := gs.githubClient.Repositories.GetContents(ctx, gs.owner, gs.repo, path, &gh.RepositoryContentGetOptions{Ref: gs.branch})
+	if err != nil {
+		return "", githubError(err)
+	}
+
+	return content.GetContent()
+}
+
+// Write commits the readme to the branch, GitHub rejects the commit with
+// ErrConflict if the file no longer has the SHA of the commit revision. In
+// pull request mode the readme is committed to the feature branch instead.
+func (gs *GitHubStorage) Write(ctx context.Context, commit Commit) (string, error) {
+	if gs.prBranch != "" {
+		return gs.writePullRequest(ctx, commit)
+	}
+
+	if gs.signer != nil {
+		return gs.writeSigned(ctx, gs.branch, commit, commit.Revision)
+	}
+
+	resp, _, err := gs.githubClient.Repositories.UpdateFile(ctx, gs.owner, gs.repo, gs.Filename(), gs.fileOptions(gs.branch, commit, commit.Revision))
+	if err != nil {
+		return "", githubError(err)
+	}
+
+	return resp.GetContent().GetSHA(), nil
+}
+
+// writeSigned commits the readme to the branch as a signed commit, on top of
+// the file with the provided SHA, and returns the SHA of the new file. The
+// branch is only fast-forwarded, so a commit pushed in between fails the
+// write with ErrConflict.
+func (gs *GitHubStorage) writeSigned(ctx context.Context, branch string, commit Commit, sha string) (string, error) {
+	ref, _, err := gs.githubClient.Git.GetRef(ctx, gs.owner, gs.repo, "heads/"+branch)
+	if err != nil {
+		return "", fmt.Errorf("failed to get branch %s: %w", branch, githubError(err))
+	}
+	parent := ref.GetObject().GetSHA()
+
+	current, _, _, err := gs.githubClient.Repositories.GetContents(ctx, gs.owner, gs.repo, gs.fileName, &gh.RepositoryContentGetOptions{Ref: parent})
+	if err != nil {
+		return "", githubError(err)
+	}
+
+	if current.GetSHA() != sha {
+		return "", ErrConflict
+	}
+
+	parentCommit, _, err := gs.githubClient.Git.GetCommit(ctx, gs.owner, gs.repo, parent)
+	if err != nil {
+		return "", githubError(err)
+	}
+
+	tree, _, err := gs.githubClient.Git.CreateTree(ctx, gs.owner, gs.repo, parentCommit.GetTree().GetSHA(), []*gh.TreeEntry{{
+		Path:    gh.String(gs.fileName),
+		Mode:    gh.String("100644"),
+		Type:    gh.String("blob"),
+		Content: gh.String(commit.Content),
+	}})
+	if err != nil {
+		return "", githubError(err)
+	}
+
+	// The signature covers the dates, so they are set explicitly rather than
+	// left to GitHub.
+	now := &gh.Timestamp{Time: time.Now().UTC().Truncate(time.Second)}
+	identity := func(i *Identity) *gh.CommitAuthor {
+		if i == nil {
+			i = &githubActionsBot
+		}
+		return &gh.CommitAuthor{Name: gh.String(i.Name), Email: gh.String(i.Email), Date: now}
+	}
+
+	message := commit.Message
+	if !strings.HasSuffix(message, "\n") {
+		message += "\n"
+	}
+
+	signed, _, err := gs.githubClient.Git.CreateCommit(ctx, gs.owner, gs.repo, &gh.Commit{
+		Message:   gh.String(message),
+		Tree:      &gh.Tree{SHA: tree.SHA},
+		Parents:   []*gh.Commit{{SHA: gh.String(parent)}},
+		Author:    identity(commit.Author),
+		Committer: identity(commit.Committer),
+	}, &gh.CreateCommitOptions{Signer: gs.signer})
+	if err != nil {
+		return "", githubError(err)
+	}
+
+	_, _, err = gs.githubClient.Git.UpdateRef(ctx, gs.owner, gs.repo, &gh.Reference{
+		Ref:    gh.String("refs/heads/" + branch),
+		Object: &gh.GitObject{SHA: signed.SHA},
+	}, false)
+	if err != nil {
+		var errResp *gh.ErrorResponse
+		if errors.As(err, &errResp) && errResp.Response != nil && errResp.Response.StatusCode == http.StatusUnprocessableEntity {
+			return "", fmt.Errorf("%w: %v", ErrConflict, err)
+		}
+		return "", githubError(err)
+	}
+
+	// The created tree only lists its top level entries, so the SHA of a
+	// readme in a subdirectory is looked up in the commit instead.
+	committed, _, _, err := gs.githubClient.Repositories.GetContents(ctx, gs.owner, gs.repo, gs.fileName, &gh.RepositoryContentGetOptions{Ref: signed.GetSHA()})
+	if err != nil {
+		return "", githubError(err)
+	}
+
+	return committed.GetSHA(), nil
+}
+
+// writePullRequest commits the readme to the feature branch, creating the
+// branch from the base branch if needed, and opens a pull request or updates
+// the description of the open one. The readme on the base branch is left as
+// is, so its revision is returned unchanged.
+func (gs *GitHubStorage) writePullRequest(ctx context.Context, commit Commit) (string, error) {
+	base, _, _, err := gs.githubClient.Repositories.GetContents(ctx, gs.owner, gs.repo, gs.fileName, &gh.RepositoryContentGetOptions{Ref: gs.branch})
+	if err != nil {
+		return "", githubError(err)
+	}
+
+	if base.GetSHA() != commit.Revision {
+		return "", ErrConflict
+	}
+
+	if err := gs.ensureBranch(ctx); err != nil {
+		return "", err
+	}
+
+	head, _, _, err := gs.githubClient.Repositories.GetContents(ctx, gs.owner, gs.repo, gs.fileName, &gh.RepositoryContentGetOptions{Ref: gs.prBranch})
+	if err != nil {
+		return "", githubError(err)
+	}
+
+	headContent, err := head.GetContent()
+	if err != nil {
+		return "", err
+	}
+
+	if headContent != commit.Content {
+		if gs.signer != nil {
+			_, err = gs.writeSigned(ctx, gs.prBranch, commit, head.GetSHA())
+		} else {
+			_, _, err = gs.githubClient.Repositories.UpdateFile(ctx, gs.owner, gs.repo, gs.fileName, gs.fileOptions(gs.prBranch, commit, head.GetSHA()))
+		}
+		if err != nil {
+			return "", githubError(err)
+		}
+	}
+
+	title, _, _ := strings.Cut(commit.Message, "\n")
+	body := commit.Changes.Summary()
+
+	pulls, _, err := gs.githubClient.PullRequests.List(ctx, gs.owner, gs.repo, &gh.PullRequestListOptions{
+		State: "open",
+		Head:  gs.owner + ":" + gs.prBranch,
+		Base:  gs.branch,
+	})
+	if err != nil {
+		return "", githubError(err)
+	}
+
+	if len(pulls) > 0 {
+		_, _, err = gs.githubClient.PullRequests.Edit(ctx, gs.owner, gs.repo, pulls[0].GetNumber(), &gh.PullRequest{
+			Title: gh.String(title),
+			Body:  gh.String(body),
+		})
+		if err != nil {
+			return "", githubError(err)
+		}
+
+		return commit.Revision, nil
+	}
+
+	_, _, err = gs.githubClient.PullRequests.Create(ctx, gs.owner, gs.repo, &gh.NewPullRequest{
+		Title: gh.String(title),
+		Head:  gh.String(gs.prBranch),
+		Base:  gh.String(gs.branch),
+		Body:  gh.String(body),
+	})
+	if err != nil {
+		return "", githubError(err)
+	}
+
+	return commit.Revision, nil
+}
+
+// ensureBranch creates the feature branch from the head of the base branch,
+// unless it already exists.
+func (gs *GitHubStorage) ensureBranch(ctx context.Context) error {
+	_, _, err := gs.githubClient.Git.GetRef(ctx, gs.owner, gs.repo, "heads/"+gs.prBranch)
+	if err == nil {
+		return nil
+	}
+
+	if err = githubError(err); !errors.Is(err, ErrNotFound) {
+		return err
+	}
+
+	baseRef, _, err := gs.githubClient.Git.GetRef(ctx, gs.owner, gs.repo, "heads/"+gs.branch)
+	if err != nil {
+		return fmt.Errorf("failed to get branch %s: %w", gs.branch, githubError(err))
+	}
+
+	_, _, err = gs.githubClient.Git.CreateRef(ctx, gs.owner, gs.repo, &gh.Reference{
+		Ref:    gh.String("refs/heads/" + gs.prBranch),
+		Object: &gh.GitObject{SHA: baseRef.GetObject().SHA},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create branch %s: %w", gs.prBranch, githubError(err))
+	}
+
+	return nil
+}
+
+// githubActionsBot is the default author and committer of commits.
+var githubActionsBot = Identity{
+	Name:  "github-actions[bot]",
+	Email: "41898282+github-actions[bot]@users.noreply.github.com",
+}
+
+// fileOptions returns the options committing the readme of the commit to the
+// branch, on top of the file with the provided SHA. In verified mode the
+// author and committer are left to GitHub.
+func (gs *GitHubStorage) fileOptions(branch string, commit Commit, sha string) *gh.RepositoryContentFileOptions {
+	identity := func(i *Identity) *gh.CommitAuthor {
+		if gs.verified {
+			return nil
+		}
+		if i == nil {
+			i = &githubActionsBot
+		}
+		return &gh.CommitAuthor{Name: gh.String(i.Name), Email: gh.String(i.Email)}
+	}
+
+	return &gh.RepositoryContentFileOptions{
+		Branch:    gh.String(branch),
+		Message:   gh.String(commit.Message),
+		Committer: identity(commit.Committer),
+		Author:    identity(commit.Author),
+		Content:   []byte(commit.Content),
+		SHA:       gh.String(sha),
+	}
+}
+
+func (gs *GitHubStorage) Filename() string {
+	return gs.fileName
+}
+
+// githubError wraps ErrConflict around responses rejecting a commit based on
+// an outdated revision, and ErrNotFound, ErrUnauthorized or ErrRateLimited
+// around other error responses where it applies.
+func githubError(err error) error {
+	var (
+		rateErr  *gh.RateLimitError
+		abuseErr *gh.AbuseRateLimitError
+		errResp  *gh.ErrorResponse
+	)
+
+	switch {
+	case errors.As(err, &rateErr), errors.As(err, &abuseErr):
+		return fmt.Errorf("%w: %v", ErrRateLimited, err)
+	case errors.As(err, &errResp) && errResp.Response != nil:
+		switch errResp.Response.StatusCode {
+		case http.StatusConflict:
+			return fmt.Errorf("%w: %v", ErrConflict, err)
+		case http.StatusNotFound:
+			return fmt.Errorf("%w: %v", ErrNotFound, err)
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return fmt.Errorf("%w: %v", ErrUnauthorized, err)
+		case http.StatusTooManyRequests:
+			return fmt.Errorf("%w: %v", ErrRateLimited, err)
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,588 @@
+package readme_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	gh "github.com/google/go-github/v64/github"
+
+	"github.com/mikejoh/go-credly/internal/readme"
+)
+
+// githubServer is a stand-in for the parts of the GitHub API used to update
+// a readme, by default the README.md of the mikejoh/mikejoh repository. It
+// keeps the content and blob SHA of the readme, and the head commit, per
+// branch.
+type githubServer struct {
+	mu         sync.Mutex
+	repo       string
+	file       string
+	n          int
+	content    map[string]string
+	shas       map[string]string
+	refs       map[string]string
+	trees      map[string][2]string
+	gitCommits map[string][2]string
+	pulls      map[string]string
+	commits    int
+	signatures []string
+	authors    []*gh.CommitAuthor
+	// races is the number of updates answered with 409 after someone else
+	// appended a line to the readme.
+	races int
+}
+
+func newGitHubServer(content string) *githubServer {
+	s := &githubServer{
+		repo:       "mikejoh/mikejoh",
+		file:       "README.md",
+		content:    make(map[string]string),
+		shas:       make(map[string]string),
+		refs:       make(map[string]string),
+		trees:      make(map[string][2]string),
+		gitCommits: make(map[string][2]string),
+		pulls:      make(map[string]string),
+	}
+	s.push("main", content)
+
+	return s
+}
+
+// push commits the content to the branch, as if pushed by someone else.
+func (s *githubServer) push(branch, content string) {
+	s.n++
+	s.content[branch] = content
+	s.shas[branch] = "blob-" + strconv.Itoa(s.n)
+	s.refs[branch] = "commit-" + strconv.Itoa(s.n)
+}
+
+// branch returns the branch with the provided name or head commit.
+func (s *githubServer) branch(ref string) (string, bool) {
+	if ref == "" {
+		ref = "main"
+	}
+	if _, ok := s.content[ref]; ok {
+		return ref, true
+	}
+	for branch, head := range s.refs {
+		if head == ref {
+			return branch, true
+		}
+	}
+	return "", false
+}
+
+func (s *githubServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path, ok := strings.CutPrefix(r.URL.Path, "/repos/"+s.repo+"/")
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	switch {
+	case path == "contents/"+s.file && r.Method == http.MethodGet:
+		branch, ok := s.branch(r.URL.Query().Get("ref"))
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"type":     "file",
+			"encoding": "base64",
+			"content":  base64.StdEncoding.EncodeToString([]byte(s.content[branch])),
+			"sha":      s.shas[branch],
+		})
+
+	case path == "contents/"+s.file && r.Method == http.MethodPut:
+		var update struct {
+			Branch    string           `json:"branch"`
+			Content   []byte           `json:"content"`
+			SHA       string           `json:"sha"`
+			Author    *gh.CommitAuthor `json:"author"`
+			Committer *gh.CommitAuthor `json:"committer"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if s.races > 0 {
+			s.races--
+			s.push(update.Branch, s.content[update.Branch]+"Edited\n")
+		}
+		if update.SHA != s.shas[update.Branch] {
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"message":"README.md does not match ` + update.SHA + `"}`))
+			return
+		}
+		s.push(update.Branch, string(update.Content))
+		s.commits++
+		s.authors = append(s.authors, update.Author)
+		_ = json.NewEncoder(w).Encode(map[string]map[string]string{"content": {"sha": s.shas[update.Branch]}})
+
+	case strings.HasPrefix(path, "git/ref/heads/") && r.Method == http.MethodGet:
+		branch := strings.TrimPrefix(path, "git/ref/heads/")
+		if _, ok := s.refs[branch]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"Not Found"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"ref": "refs/heads/" + branch, "object": map[string]string{"sha": s.refs[branch]}})
+
+	case path == "git/refs" && r.Method == http.MethodPost:
+		var ref struct {
+			Ref string `json:"ref"`
+			SHA string `json:"sha"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&ref); err != nil || ref.SHA != s.refs["main"] {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return
+		}
+		branch := strings.TrimPrefix(ref.Ref, "refs/heads/")
+		s.content[branch], s.shas[branch], s.refs[branch] = s.content["main"], s.shas["main"], s.refs["main"]
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]any{"ref": ref.Ref, "object": map[string]string{"sha": ref.SHA}})
+
+	case strings.HasPrefix(path, "git/commits/") && r.Method == http.MethodGet:
+		sha := strings.TrimPrefix(path, "git/commits/")
+		_ = json.NewEncoder(w).Encode(map[string]any{"sha": sha, "tree": map[string]string{"sha": "tree-" + sha}})
+
+	case path == "git/trees" && r.Method == http.MethodPost:
+		var tree struct {
+			BaseTree string `json:"base_tree"`
+			Entries  []struct {
+				Path    string `json:"path"`
+				Content string `json:"content"`
+			} `json:"tree"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&tree); err != nil || len(tree.Entries) != 1 || tree.Entries[0].Path != s.file {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return
+		}
+		s.n++
+		sha, blob := "tree-"+strconv.Itoa(s.n), "blob-"+strconv.Itoa(s.n)
+		s.trees[sha] = [2]string{tree.Entries[0].Content, blob}
+		// Like GitHub, only the top level entries of the tree are listed.
+		entry := map[string]string{"path": s.file, "type": "blob", "sha": blob}
+		if dir, _, ok := strings.Cut(s.file, "/"); ok {
+			entry = map[string]string{"path": dir, "type": "tree", "sha": sha + "-" + dir}
+		}
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]any{"sha": sha, "tree": []map[string]string{entry}})
+
+	case path == "git/commits" && r.Method == http.MethodPost:
+		var commit struct {
+			Tree      string           `json:"tree"`
+			Parents   []string         `json:"parents"`
+			Author    *gh.CommitAuthor `json:"author"`
+			Signature string           `json:"signature"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&commit); err != nil || len(commit.Parents) != 1 {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return
+		}
+		s.n++
+		sha := "signed-" + strconv.Itoa(s.n)
+		s.gitCommits[sha] = [2]string{commit.Tree, commit.Parents[0]}
+		s.signatures = append(s.signatures, commit.Signature)
+		s.authors = append(s.authors, commit.Author)
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]string{"sha": sha})
+
+	case strings.HasPrefix(path, "git/refs/heads/") && r.Method == http.MethodPatch:
+		branch := strings.TrimPrefix(path, "git/refs/heads/")
+		var ref struct {
+			SHA string `json:"sha"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&ref); err != nil {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return
+		}
+		commit := s.gitCommits[ref.SHA]
+		if commit[1] != s.refs[branch] {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = w.Write([]byte(`{"message":"Update is not a fast forward"}`))
+			return
+		}
+		tree := s.trees[commit[0]]
+		s.content[branch], s.shas[branch], s.refs[branch] = tree[0], tree[1], ref.SHA
+		s.commits++
+		_ = json.NewEncoder(w).Encode(map[string]any{"ref": "refs/heads/" + branch, "object": map[string]string{"sha": ref.SHA}})
+
+	case path == "pulls" && r.Method == http.MethodGet:
+		var pulls []map[string]any
+		head := strings.TrimPrefix(r.URL.Query().Get("head"), "mikejoh:")
+		if _, ok := s.pulls[head]; ok && r.URL.Query().Get("base") == "main" {
+			pulls = append(pulls, map[string]any{"number": 1})
+		}
+		_ = json.NewEncoder(w).Encode(pulls)
+
+	case path == "pulls" && r.Method == http.MethodPost:
+		var pull struct {
+			Head string `json:"head"`
+			Base string `json:"base"`
+			Body string `json:"body"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&pull); err != nil || pull.Base != "main" {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return
+		}
+		s.pulls[pull.Head] = pull.Body
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]any{"number": 1})
+
+	case path == "pulls/1" && r.Method == http.MethodPatch:
+		var pull struct {
+			Body string `json:"body"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&pull); err != nil {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return
+		}
+		s.pulls["credly-badges"] = pull.Body
+		_ = json.NewEncoder(w).Encode(map[string]any{"number": 1})
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newGitHubClient(t *testing.T, srv *httptest.Server) *gh.Client {
+	t.Helper()
+
+	client := gh.NewClient(nil)
+
+	baseURL, err := url.Parse(srv.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.BaseURL = baseURL
+
+	return client
+}
+
+func TestGitHubStoragePullRequest(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	original := "# Hi\n<!--START_BADGES:badges layout=list-->\n<!--END_BADGES:badges-->\n"
+	stub := newGitHubServer(original)
+	srv := httptest.NewServer(stub)
+	defer srv.Close()
+
+	storage := readme.NewGitHubStorage("mikejoh", "mikejoh").
+		WithGitHubClient(newGitHubClient(t, srv)).
+		WithPullRequest("credly-badges")
+
+	sections := []readme.Section{{Name: readme.DefaultSection}}
+
+	r := readme.New(storage)
+	if err := r.Fetch(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if err := r.WriteSections(testBadges[:1], sections); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if err := r.Update(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if stub.content["main"] != original {
+		t.Fatalf("expected the base branch to be left as is, got %q", stub.content["main"])
+	}
+
+	if !strings.Contains(stub.content["credly-badges"], "CKA: Certified Kubernetes Administrator") {
+		t.Fatalf("expected the readme to be committed to the feature branch, got %q", stub.content["credly-badges"])
+	}
+
+	if !strings.Contains(stub.pulls["credly-badges"], "**Added (1)**\n\n- CKA: Certified Kubernetes Administrator\n") {
+		t.Fatalf("expected the pull request to summarize the added badge, got %q", stub.pulls["credly-badges"])
+	}
+
+	// A later run reuses the branch and updates the open pull request.
+	r = readme.New(storage).WithRetries(0)
+	if err := r.Fetch(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if err := r.WriteSections(testBadges[1:], sections); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if err := r.Update(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if stub.commits != 2 {
+		t.Fatalf("expected 2 commits to the feature branch, got %d", stub.commits)
+	}
+
+	if !strings.Contains(stub.pulls["credly-badges"], "KCNA: Kubernetes and Cloud Native Associate") {
+		t.Fatalf("expected the pull request description to be updated, got %q", stub.pulls["credly-badges"])
+	}
+
+	stub.push("main", original+"Edited\n")
+
+	if err := r.Update(ctx); !errors.Is(err, readme.ErrConflict) {
+		t.Fatalf("expected %v, got %v", readme.ErrConflict, err)
+	}
+}
+
+func TestGitHubStorageCommits(t *testing.T) {
+	original := "# Hi\n<!--START_BADGES:badges layout=list-->\n<!--END_BADGES:badges-->\n"
+
+	tt := []struct {
+		name     string
+		storage  func(*readme.GitHubStorage) *readme.GitHubStorage
+		author   *readme.Identity
+		signed   bool
+		expected *gh.CommitAuthor
+	}{
+		{
+			name:     "default identity",
+			storage:  func(gs *readme.GitHubStorage) *readme.GitHubStorage { return gs },
+			expected: &gh.CommitAuthor{Name: gh.String("github-actions[bot]")},
+		},
+		{
+			name:     "custom identity",
+			storage:  func(gs *readme.GitHubStorage) *readme.GitHubStorage { return gs },
+			author:   &readme.Identity{Name: "Mike", Email: "mike@example.com"},
+			expected: &gh.CommitAuthor{Name: gh.String("Mike")},
+		},
+		{
+			name:    "verified",
+			storage: func(gs *readme.GitHubStorage) *readme.GitHubStorage { return gs.WithVerifiedCommits() },
+		},
+		{
+			name: "signed",
+			storage: func(gs *readme.GitHubStorage) *readme.GitHubStorage {
+				return gs.WithSigner(readme.CommandSigner{Program: "tr", Args: []string{"a-z", "A-Z"}})
+			},
+			author:   &readme.Identity{Name: "Mike", Email: "mike@example.com"},
+			signed:   true,
+			expected: &gh.CommitAuthor{Name: gh.String("Mike")},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			stub := newGitHubServer(original)
+			srv := httptest.NewServer(stub)
+			defer srv.Close()
+
+			storage := tc.storage(readme.NewGitHubStorage("mikejoh", "mikejoh").WithGitHubClient(newGitHubClient(t, srv)))
+
+			r := readme.New(storage).WithRetries(0)
+			if tc.author != nil {
+				r.WithAuthor(*tc.author)
+			}
+
+			if err := r.Fetch(ctx); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			sections := []readme.Section{{Name: readme.DefaultSection}}
+			if err := r.WriteSections(testBadges[:1], sections); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if err := r.Update(ctx); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if stub.content["main"] != r.Get() {
+				t.Fatalf("expected %q, got %q", r.Get(), stub.content["main"])
+			}
+
+			if len(stub.authors) != 1 {
+				t.Fatalf("expected 1 commit, got %d", len(stub.authors))
+			}
+
+			author := stub.authors[0]
+			if (author == nil) != (tc.expected == nil) || (author != nil && author.GetName() != tc.expected.GetName()) {
+				t.Fatalf("expected author %v, got %v", tc.expected, author)
+			}
+
+			if tc.signed && (len(stub.signatures) != 1 || !strings.HasPrefix(stub.signatures[0], "TREE TREE-")) {
+				t.Fatalf("expected the commit to be signed, got %q", stub.signatures)
+			}
+
+			// The revision follows the commit, a second update succeeds while
+			// one based on a commit pushed in between fails.
+			if err := r.WriteSections(testBadges, sections); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if err := r.Update(ctx); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			stub.push("main", original)
+
+			if err := r.WriteSections(testBadges[1:], sections); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if err := r.Update(ctx); !errors.Is(err, readme.ErrConflict) {
+				t.Fatalf("expected %v, got %v", readme.ErrConflict, err)
+			}
+		})
+	}
+}
+
+func TestReadmeConflictRetry(t *testing.T) {
+	original := "# Hi\n<!--START_BADGES:badges layout=list-->\n<!--END_BADGES:badges-->\n"
+	badges := "- [CKA: Certified Kubernetes Administrator](https://www.credly.com/badges/20f4aaea-770e-4e32-8cd0-f2720fb11d85), The Linux Foundation (2023-03-14)\n"
+
+	tt := []struct {
+		name     string
+		races    int
+		retries  int
+		expected string
+		err      error
+	}{
+		{
+			name:     "no conflict",
+			retries:  3,
+			expected: "# Hi\n<!--START_BADGES:badges layout=list-->\n" + badges + "<!--END_BADGES:badges-->\n",
+		},
+		{
+			name:     "conflicts within retries",
+			races:    2,
+			retries:  3,
+			expected: "# Hi\n<!--START_BADGES:badges layout=list-->\n" + badges + "<!--END_BADGES:badges-->\nEdited\nEdited\n",
+		},
+		{
+			name:     "conflicts exceed retries",
+			races:    3,
+			retries:  2,
+			expected: original + "Edited\nEdited\nEdited\n",
+			err:      readme.ErrConflict,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			stub := newGitHubServer(original)
+			stub.races = tc.races
+			srv := httptest.NewServer(stub)
+			defer srv.Close()
+
+			storage := readme.NewGitHubStorage("mikejoh", "mikejoh").WithGitHubClient(newGitHubClient(t, srv))
+
+			r := readme.New(storage).WithRetries(tc.retries)
+			if err := r.Fetch(ctx); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if err := r.WriteSections(testBadges[:1], []readme.Section{{Name: readme.DefaultSection}}); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			err := r.Update(ctx)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected %v, got %v", tc.err, err)
+			}
+
+			if stub.content["main"] != tc.expected {
+				t.Fatalf("expected %q, got %q", tc.expected, stub.content["main"])
+			}
+		})
+	}
+}
+
+func TestGitHubStorageRepository(t *testing.T) {
+	original := "# Alice\n<!--START_BADGES:badges layout=list-->\n<!--END_BADGES:badges-->\n"
+
+	tt := []struct {
+		name    string
+		storage func(*readme.GitHubStorage) *readme.GitHubStorage
+	}{
+		{
+			name:    "contents API",
+			storage: func(gs *readme.GitHubStorage) *readme.GitHubStorage { return gs },
+		},
+		{
+			name: "signed",
+			storage: func(gs *readme.GitHubStorage) *readme.GitHubStorage {
+				return gs.WithSigner(readme.CommandSigner{Program: "tr", Args: []string{"a-z", "A-Z"}})
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			stub := newGitHubServer("# Team handbook\n")
+			stub.repo, stub.file = "acme/team-handbook", "docs/people/alice.md"
+			stub.push("docs", original)
+			srv := httptest.NewServer(stub)
+			defer srv.Close()
+
+			storage := tc.storage(readme.NewGitHubStorage("acme", "team-handbook").
+				WithGitHubClient(newGitHubClient(t, srv)).
+				WithFileName("docs/people/alice.md").
+				WithBranch("docs"))
+
+			r := readme.New(storage).WithRetries(0)
+			if err := r.Fetch(ctx); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if r.Get() != original {
+				t.Fatalf("expected the readme to be fetched from the branch, got %q", r.Get())
+			}
+
+			sections := []readme.Section{{Name: readme.DefaultSection}}
+			if err := r.WriteSections(testBadges[:1], sections); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if err := r.Update(ctx); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if stub.content["docs"] != r.Get() {
+				t.Fatalf("expected %q, got %q", r.Get(), stub.content["docs"])
+			}
+
+			if stub.content["main"] != "# Team handbook\n" {
+				t.Fatalf("expected the main branch to be left as is, got %q", stub.content["main"])
+			}
+
+			// The revision follows the commit, so a second update succeeds.
+			if err := r.WriteSections(testBadges, sections); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if err := r.Update(ctx); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if stub.content["docs"] != r.Get() {
+				t.Fatalf("expected %q, got %q", r.Get(), stub.content["docs"])
+			}
+		})
+	}
+}
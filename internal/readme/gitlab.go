@@ -0,0 +1,183 @@
+package readme
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const gitlabBaseURL = "https://gitlab.com/api/v4/"
+
+// GitLabStorage stores the readme in a GitLab project, using the repository
+// files API. Profile readmes live in the username/username project.
+type GitLabStorage struct {
+	baseURL  string
+	client   http.Client
+	token    string
+	project  string
+	fileName string
+	branch   string
+}
+
+func NewGitLabStorage(owner, repo string) *GitLabStorage {
+	return &GitLabStorage{
+		baseURL:  gitlabBaseURL,
+		client:   http.Client{},
+		project:  owner + "/" + repo,
+		fileName: "README.md",
+		branch:   "main",
+	}
+}
+
+func (gs *GitLabStorage) WithHTTPClient(client http.Client) *GitLabStorage {
+	gs.client = client
+	return gs
+}
+
+// WithBaseURL sets the URL of the GitLab API, e.g.
+// https://gitlab.example.com/api/v4/ for a self-managed instance.
+func (gs *GitLabStorage) WithBaseURL(baseURL string) *GitLabStorage {
+	gs.baseURL = baseURL
+	return gs
+}
+
+func (gs *GitLabStorage) WithToken(token string) *GitLabStorage {
+	gs.token = token
+	return gs
+}
+
+func (gs *GitLabStorage) WithFileName(filename string) *GitLabStorage {
+	gs.fileName = filename
+	return gs
+}
+
+func (gs *GitLabStorage) WithBranch(branch string) *GitLabStorage {
+	gs.branch = branch
+	return gs
+}
+
+// gitlabFile is a file returned by the repository files API.
+type gitlabFile struct {
+	Encoding     string `json:"encoding"`
+	Content      string `json:"content"`
+	LastCommitID string `json:"last_commit_id"`
+}
+
+// gitlabUpdate is the body of a repository files API update request.
+type gitlabUpdate struct {
+	Branch        string `json:"branch"`
+	Content       string `json:"content"`
+	CommitMessage string `json:"commit_message"`
+	LastCommitID  string `json:"last_commit_id,omitempty"`
+	AuthorName    string `json:"author_name,omitempty"`
+	AuthorEmail   string `json:"author_email,omitempty"`
+}
+
+// Fetch fetches the readme from the branch, its revision is the id of the
+// last commit changing the file.
+func (gs *GitLabStorage) Fetch(ctx context.Context) (File, error) {
+	file, err := gs.getFile(ctx, gs.fileName)
+	if err != nil {
+		return File{}, err
+	}
+
+	content, err := decodeContent(file.Encoding, file.Content)
+	if err != nil {
+		return File{}, err
+	}
+
+	return File{Content: content, Revision: file.LastCommitID}, nil
+}
+
+// FetchFile fetches the content of another file in the project, such as a
+// badge template.
+func (gs *GitLabStorage) FetchFile(ctx context.Context, path string) (string, error) {
+	file, err := gs.getFile(ctx, path)
+	if err != nil {
+		return "", err
+	}
+
+	return decodeContent(file.Encoding, file.Content)
+}
+
+// Write commits the readme to the branch. GitLab rejects the commit with
+// ErrConflict if the file was changed after the commit revision. The
+// committer is always the owner of the token, only the author can be set.
+func (gs *GitLabStorage) Write(ctx context.Context, commit Commit) (string, error) {
+	update := gitlabUpdate{
+		Branch:        gs.branch,
+		Content:       commit.Content,
+		CommitMessage: commit.Message,
+		LastCommitID:  commit.Revision,
+	}
+	if commit.Author != nil {
+		update.AuthorName, update.AuthorEmail = commit.Author.Name, commit.Author.Email
+	}
+
+	body, err := json.Marshal(update)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := gs.do(ctx, http.MethodPut, gs.fileURL(gs.fileName), bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+
+	// The update response only holds the file path and branch, the new
+	// revision is read from the headers of the file instead.
+	resp, err = gs.do(ctx, http.MethodHead, gs.fileURL(gs.fileName)+"?ref="+url.QueryEscape(gs.branch), http.NoBody)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+
+	return resp.Header.Get("X-Gitlab-Last-Commit-Id"), nil
+}
+
+func (gs *GitLabStorage) Filename() string {
+	return gs.fileName
+}
+
+func (gs *GitLabStorage) getFile(ctx context.Context, path string) (*gitlabFile, error) {
+	resp, err := gs.do(ctx, http.MethodGet, gs.fileURL(path)+"?ref="+url.QueryEscape(gs.branch), http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var file gitlabFile
+	if err := json.NewDecoder(resp.Body).Decode(&file); err != nil {
+		return nil, fmt.Errorf("failed to decode GitLab file %s: %w", path, err)
+	}
+
+	return &file, nil
+}
+
+// fileURL returns the repository files API URL of the file in the project.
+func (gs *GitLabStorage) fileURL(path string) string {
+	return strings.TrimSuffix(gs.baseURL, "/") + "/projects/" + url.PathEscape(gs.project) + "/repository/files/" + url.PathEscape(path)
+}
+
+// do sends a request to the GitLab API. GitLab answers an update based on an
+// outdated last commit id with 400 and a message rather than 409.
+func (gs *GitLabStorage) do(ctx context.Context, method, urlString string, body io.Reader) (*http.Response, error) {
+	api := forgeAPI{
+		client:     &gs.client,
+		authHeader: "PRIVATE-TOKEN",
+		conflict: func(code int, message string) bool {
+			return code == http.StatusConflict || strings.Contains(message, "changed since")
+		},
+	}
+	if gs.token != "" {
+		api.authValue = gs.token
+	}
+
+	return api.do(ctx, method, urlString, body)
+}
@@ -0,0 +1,133 @@
+package readme_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/mikejoh/go-credly/internal/readme"
+)
+
+// gitlabServer is a stand-in for the GitLab repository files API holding a
+// single README.md on the main branch of the mikejoh/mikejoh project.
+type gitlabServer struct {
+	mu      sync.Mutex
+	content string
+	commit  int
+}
+
+func (s *gitlabServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.Header.Get("PRIVATE-TOKEN") != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	if r.URL.EscapedPath() != "/api/v4/projects/mikejoh%2Fmikejoh/repository/files/README.md" {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message":"404 File Not Found"}`))
+		return
+	}
+
+	lastCommitID := "commit-" + strconv.Itoa(s.commit)
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		if r.URL.Query().Get("ref") != "main" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("X-Gitlab-Last-Commit-Id", lastCommitID)
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"encoding":       "base64",
+			"content":        base64.StdEncoding.EncodeToString([]byte(s.content)),
+			"last_commit_id": lastCommitID,
+		})
+	case http.MethodPut:
+		var update struct {
+			Branch        string `json:"branch"`
+			Content       string `json:"content"`
+			CommitMessage string `json:"commit_message"`
+			LastCommitID  string `json:"last_commit_id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&update); err != nil || update.Branch != "main" || update.CommitMessage == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if update.LastCommitID != lastCommitID {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"message":"You are attempting to update a file that has changed since you started editing it."}`))
+			return
+		}
+		s.content = update.Content
+		s.commit++
+		_, _ = w.Write([]byte(`{"file_path":"README.md","branch":"main"}`))
+	}
+}
+
+func TestGitLabStorage(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	stub := &gitlabServer{content: "# Hi\n<!--START_BADGES:badges layout=list-->\n<!--END_BADGES:badges-->\n", commit: 1}
+	srv := httptest.NewServer(stub)
+	defer srv.Close()
+
+	newStorage := func() *readme.GitLabStorage {
+		return readme.NewGitLabStorage("mikejoh", "mikejoh").
+			WithBaseURL(srv.URL + "/api/v4/").
+			WithToken("secret")
+	}
+
+	r := readme.New(newStorage())
+	if err := r.Fetch(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	stale := readme.New(newStorage()).WithRetries(0)
+	if err := stale.Fetch(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	sections := []readme.Section{{Name: readme.DefaultSection}}
+	if err := r.WriteSections(testBadges[:1], sections); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if err := r.Update(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := "# Hi\n<!--START_BADGES:badges layout=list-->\n- [CKA: Certified Kubernetes Administrator](https://www.credly.com/badges/20f4aaea-770e-4e32-8cd0-f2720fb11d85), The Linux Foundation (2023-03-14)\n<!--END_BADGES:badges-->\n"
+	if stub.content != expected {
+		t.Fatalf("expected %q, got %q", expected, stub.content)
+	}
+
+	if err := stale.WriteSections(testBadges, sections); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if err := stale.Update(ctx); !errors.Is(err, readme.ErrConflict) {
+		t.Fatalf("expected %v, got %v", readme.ErrConflict, err)
+	}
+
+	if err := r.WriteSections(testBadges, sections); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if err := r.Update(ctx); err != nil {
+		t.Fatalf("expected the revision to follow the update, got %v", err)
+	}
+
+	if _, err := readme.New(newStorage().WithToken("wrong")).FetchFile(ctx, "README.md"); !errors.Is(err, readme.ErrUnauthorized) {
+		t.Fatalf("expected %v, got %v", readme.ErrUnauthorized, err)
+	}
+}
@@ -0,0 +1,88 @@
+package readme
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+// LocalStorage stores the readme on the local filesystem, e.g. in a checked
+// out repository.
+type LocalStorage struct {
+	path string
+}
+
+func NewLocalStorage(path string) *LocalStorage {
+	return &LocalStorage{path: path}
+}
+
+// Fetch reads the readme, its revision is the SHA-256 of the content.
+func (ls *LocalStorage) Fetch(_ context.Context) (File, error) {
+	content, err := os.ReadFile(ls.path)
+	if err != nil {
+		return File{}, localError(err)
+	}
+
+	return File{Content: string(content), Revision: contentRevision(content)}, nil
+}
+
+// FetchFile reads another local file, such as a badge template. Relative
+// paths are relative to the working directory.
+func (ls *LocalStorage) FetchFile(_ context.Context, path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", localError(err)
+	}
+
+	return string(content), nil
+}
+
+// Write writes the readme, keeping the mode of the file. The write fails
+// with ErrConflict if the file was changed since the commit revision.
+func (ls *LocalStorage) Write(_ context.Context, commit Commit) (string, error) {
+	info, err := os.Stat(ls.path)
+	if err != nil {
+		return "", localError(err)
+	}
+
+	content, err := os.ReadFile(ls.path)
+	if err != nil {
+		return "", localError(err)
+	}
+
+	if commit.Revision != "" && contentRevision(content) != commit.Revision {
+		return "", ErrConflict
+	}
+
+	if err := os.WriteFile(ls.path, []byte(commit.Content), info.Mode().Perm()); err != nil {
+		return "", localError(err)
+	}
+
+	return contentRevision([]byte(commit.Content)), nil
+}
+
+func (ls *LocalStorage) Filename() string {
+	return ls.path
+}
+
+// localError wraps ErrNotFound and ErrUnauthorized around errors for missing
+// files and files that can't be read.
+func localError(err error) error {
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	case errors.Is(err, fs.ErrPermission):
+		return fmt.Errorf("%w: %w", ErrUnauthorized, err)
+	}
+
+	return err
+}
+
+func contentRevision(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,68 @@
+package readme_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mikejoh/go-credly/internal/readme"
+)
+
+func TestLocalReadme(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "README.md")
+
+	original := "# Hi\n<!--START_BADGES:badges layout=list-->\n<!--END_BADGES:badges-->\n"
+	if err := os.WriteFile(path, []byte(original), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	lr := readme.New(readme.NewLocalStorage(path)).WithRetries(0)
+	if err := lr.Fetch(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	sections := []readme.Section{{Name: readme.DefaultSection}}
+	if err := lr.WriteSections(testBadges[:1], sections); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if err := lr.Update(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "# Hi\n<!--START_BADGES:badges layout=list-->\n- [CKA: Certified Kubernetes Administrator](https://www.credly.com/badges/20f4aaea-770e-4e32-8cd0-f2720fb11d85), The Linux Foundation (2023-03-14)\n<!--END_BADGES:badges-->\n"
+	if string(content) != expected {
+		t.Fatalf("expected %q, got %q", expected, content)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected file mode to be kept, got %s", info.Mode().Perm())
+	}
+
+	if err := lr.WriteSections(testBadges[:1], sections); !errors.Is(err, readme.ErrFilesAreEqual) {
+		t.Fatalf("expected %v, got %v", readme.ErrFilesAreEqual, err)
+	}
+
+	if err := os.WriteFile(path, []byte(original+"Edited\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := lr.Update(ctx); !errors.Is(err, readme.ErrConflict) {
+		t.Fatalf("expected %v, got %v", readme.ErrConflict, err)
+	}
+}
@@ -0,0 +1,340 @@
+package readme
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// anyStartRe matches the start marker of any named section, capturing the
+// section name.
+var anyStartRe = regexp.MustCompile(`<!--START_BADGES:([^\s>]+?)(?:\s|-->)`)
+
+// ErrMarkersMissing matches a MarkerError for a section missing its start or
+// end marker.
+var ErrMarkersMissing = errors.New("markers missing")
+
+// MarkerErrorKind describes what is wrong with the markers of a section.
+type MarkerErrorKind int
+
+const (
+	MissingStart MarkerErrorKind = iota + 1
+	MissingEnd
+	DuplicateStart
+	DuplicateEnd
+	EndBeforeStart
+	NestedSection
+	InvalidAttributes
+)
+
+func (k MarkerErrorKind) String() string {
+	switch k {
+	case MissingStart:
+		return "start marker not found"
+	case MissingEnd:
+		return "end marker not found"
+	case DuplicateStart:
+		return "duplicate start marker"
+	case DuplicateEnd:
+		return "duplicate end marker"
+	case EndBeforeStart:
+		return "end marker before start marker"
+	case NestedSection:
+		return "start marker of another section inside section"
+	case InvalidAttributes:
+		return "invalid attributes in start marker"
+	}
+	return "invalid markers"
+}
+
+// MarkerError describes invalid markers of a section. Line is the 1-based
+// line of the offending marker, and OtherLine the line of the marker it
+// conflicts with, if any.
+type MarkerError struct {
+	Kind      MarkerErrorKind
+	Section   string
+	Marker    string
+	Line      int
+	OtherLine int
+	Err       error
+}
+
+func (e *MarkerError) Error() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "section %s: %s", e.Section, e.Kind)
+	if e.Marker != "" {
+		fmt.Fprintf(&sb, " %s", e.Marker)
+	}
+	if e.Line > 0 {
+		fmt.Fprintf(&sb, " on line %d", e.Line)
+	}
+	if e.OtherLine > 0 {
+		fmt.Fprintf(&sb, " (see line %d)", e.OtherLine)
+	}
+	if e.Err != nil {
+		fmt.Fprintf(&sb, ": %v", e.Err)
+	}
+
+	return sb.String()
+}
+
+func (e *MarkerError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the error is ErrMarkersMissing, for missing markers.
+func (e *MarkerError) Is(target error) bool {
+	return target == ErrMarkersMissing && (e.Kind == MissingStart || e.Kind == MissingEnd)
+}
+
+// markers matches the start and end marker of a section.
+type markers struct {
+	section string
+	start   *regexp.Regexp
+	end     *regexp.Regexp
+}
+
+// sectionMarkers returns the markers of the named section. The start marker
+// may carry attributes after the section name.
+func sectionMarkers(name string) markers {
+	return markers{
+		section: name,
+		start:   regexp.MustCompile(`<!--START_BADGES:` + regexp.QuoteMeta(name) + `(?:\s.*?)?-->`),
+		end:     regexp.MustCompile(`<!--END_BADGES:` + regexp.QuoteMeta(name) + `\s*-->`),
+	}
+}
+
+// literalMarkers returns markers matching the provided strings exactly.
+func literalMarkers(start, end string) markers {
+	return markers{
+		section: start,
+		start:   regexp.MustCompile(regexp.QuoteMeta(start)),
+		end:     regexp.MustCompile(regexp.QuoteMeta(end)),
+	}
+}
+
+// match is a marker found in the readme.
+type match struct {
+	line       int
+	start, end int
+	text       string
+}
+
+// span is the location of a section in the readme.
+type span struct {
+	start match
+	end   match
+}
+
+// eachLine calls fn with every line of the readme outside fenced code
+// blocks, along with its 1-based line number and byte offset.
+func eachLine(readme string, fn func(number, offset int, line string)) {
+	inFence := false
+	var fence string
+
+	offset := 0
+	for i, line := range strings.SplitAfter(readme, "\n") {
+		lineOffset := offset
+		offset += len(line)
+
+		trimmed := strings.TrimLeft(line, " ")
+		if len(line)-len(trimmed) <= 3 {
+			if f := fenceOf(trimmed); f != "" {
+				switch {
+				case !inFence:
+					inFence, fence = true, f
+				case f[0] == fence[0] && len(f) >= len(fence) && strings.TrimSpace(trimmed[len(f):]) == "":
+					inFence = false
+				}
+				continue
+			}
+		}
+
+		if !inFence {
+			fn(i+1, lineOffset, line)
+		}
+	}
+}
+
+// SectionNames returns the names of the badge sections started in the
+// readme, in order of appearance. Markers in fenced code blocks are ignored.
+func SectionNames(readme string) []string {
+	var names []string
+
+	seen := make(map[string]bool)
+	eachLine(readme, func(_, _ int, line string) {
+		for _, m := range anyStartRe.FindAllStringSubmatch(line, -1) {
+			if !seen[m[1]] {
+				seen[m[1]] = true
+				names = append(names, m[1])
+			}
+		}
+	})
+
+	return names
+}
+
+// findSection locates the section delimited by the markers, ignoring markers
+// inside fenced code blocks.
+func findSection(readme string, m markers) (span, error) {
+	var starts, ends, others []match
+
+	eachLine(readme, func(number, offset int, line string) {
+		find := func(re *regexp.Regexp) []match {
+			var matches []match
+			for _, loc := range re.FindAllStringIndex(line, -1) {
+				matches = append(matches, match{
+					line:  number,
+					start: offset + loc[0],
+					end:   offset + loc[1],
+					text:  line[loc[0]:loc[1]],
+				})
+			}
+			return matches
+		}
+
+		startMatches := find(m.start)
+		starts = append(starts, startMatches...)
+		ends = append(ends, find(m.end)...)
+
+		for _, other := range find(anyStartRe) {
+			isOwn := false
+			for _, s := range startMatches {
+				if s.start == other.start {
+					isOwn = true
+				}
+			}
+			if !isOwn {
+				others = append(others, other)
+			}
+		}
+	})
+
+	switch {
+	case len(starts) == 0:
+		return span{}, &MarkerError{Kind: MissingStart, Section: m.section}
+	case len(starts) > 1:
+		return span{}, &MarkerError{Kind: DuplicateStart, Section: m.section, Marker: starts[1].text, Line: starts[1].line, OtherLine: starts[0].line}
+	case len(ends) == 0:
+		return span{}, &MarkerError{Kind: MissingEnd, Section: m.section, OtherLine: starts[0].line}
+	case len(ends) > 1:
+		return span{}, &MarkerError{Kind: DuplicateEnd, Section: m.section, Marker: ends[1].text, Line: ends[1].line, OtherLine: ends[0].line}
+	}
+
+	s := span{start: starts[0], end: ends[0]}
+	if s.end.start < s.start.end {
+		return span{}, &MarkerError{Kind: EndBeforeStart, Section: m.section, Marker: s.end.text, Line: s.end.line, OtherLine: s.start.line}
+	}
+
+	for _, other := range others {
+		if other.start > s.start.start && other.start < s.end.start {
+			return span{}, &MarkerError{Kind: NestedSection, Section: m.section, Marker: strings.TrimSuffix(other.text, "-->"), Line: other.line, OtherLine: s.start.line}
+		}
+	}
+
+	return s, nil
+}
+
+// sectionContent returns the content of the sections delimited by the
+// markers, leaving out sections not found in the readme.
+func sectionContent(readme string, ms []markers) string {
+	var sb strings.Builder
+
+	for _, m := range ms {
+		s, err := findSection(readme, m)
+		if err != nil {
+			continue
+		}
+		sb.WriteString(readme[s.start.end:s.end.start])
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+// attributes parses the attributes of the start marker of a named section,
+// e.g. layout=table issuer="The Linux Foundation".
+func (s span) attributes(section string) (map[string]string, error) {
+	text := strings.TrimPrefix(s.start.text, "<!--START_BADGES:"+section)
+	text = strings.TrimSuffix(text, "-->")
+
+	attrs, err := parseAttributes(text)
+	if err != nil {
+		return nil, &MarkerError{Kind: InvalidAttributes, Section: section, Line: s.start.line, Err: err}
+	}
+
+	return attrs, nil
+}
+
+// parseAttributes parses space separated key=value pairs, values containing
+// spaces are quoted with double or single quotes.
+func parseAttributes(text string) (map[string]string, error) {
+	attrs := make(map[string]string)
+
+	rest := strings.TrimSpace(text)
+	for rest != "" {
+		eq := strings.IndexByte(rest, '=')
+		if eq <= 0 || strings.ContainsAny(rest[:eq], " \t\"'") {
+			key, _, _ := strings.Cut(rest, " ")
+			return nil, fmt.Errorf("attribute %q has no value", key)
+		}
+
+		key := rest[:eq]
+		rest = rest[eq+1:]
+
+		var value string
+		if rest != "" && (rest[0] == '"' || rest[0] == '\'') {
+			end := strings.IndexByte(rest[1:], rest[0])
+			if end == -1 {
+				return nil, fmt.Errorf("attribute %q has an unterminated quoted value", key)
+			}
+			value = rest[1 : end+1]
+			rest = rest[end+2:]
+		} else {
+			value, rest, _ = strings.Cut(rest, " ")
+		}
+
+		if _, ok := attrs[key]; ok {
+			return nil, fmt.Errorf("attribute %q is set more than once", key)
+		}
+		attrs[key] = value
+
+		rest = strings.TrimSpace(rest)
+	}
+
+	return attrs, nil
+}
+
+// replace replaces the content of the located section, keeping the markers as
+// they are.
+func (s span) replace(readme, content string) string {
+	return readme[:s.start.end] + "\n" + content + readme[s.end.start:]
+}
+
+// fenceOf returns the code fence opening the line, if any.
+func fenceOf(line string) string {
+	for _, c := range []string{"`", "~"} {
+		n := len(line) - len(strings.TrimLeft(line, c))
+		if n >= 3 {
+			return line[:n]
+		}
+	}
+	return ""
+}
+
+// splice replaces the content between the markers of a section, keeping the
+// markers as they are.
+func splice(readme string, m markers, content string) (string, error) {
+	if readme == "" {
+		return "", errors.New("readme cannot be empty")
+	}
+
+	s, err := findSection(readme, m)
+	if err != nil {
+		return "", err
+	}
+
+	return s.replace(readme, content), nil
+}
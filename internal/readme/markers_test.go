@@ -0,0 +1,228 @@
+package readme
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/mikejoh/go-credly/internal/credly"
+)
+
+func TestSplice(t *testing.T) {
+	tt := []struct {
+		name     string
+		readme   string
+		section  string
+		expected string
+		kind     MarkerErrorKind
+		line     int
+	}{
+		{
+			name:     "replace section",
+			readme:   "# Hi\n<!--START_BADGES:badges-->\nold\n<!--END_BADGES:badges-->\nbye\n",
+			section:  "badges",
+			expected: "# Hi\n<!--START_BADGES:badges-->\nnew\n<!--END_BADGES:badges-->\nbye\n",
+		},
+
+		{
+			name:     "markers on the same line",
+			readme:   "<!--START_BADGES:badges--><!--END_BADGES:badges-->",
+			section:  "badges",
+			expected: "<!--START_BADGES:badges-->\nnew\n<!--END_BADGES:badges-->",
+		},
+
+		{
+			name:     "markers in code fence are ignored",
+			readme:   "```\n<!--START_BADGES:badges-->\n<!--END_BADGES:badges-->\n```\n<!--START_BADGES:badges-->\n<!--END_BADGES:badges-->\n",
+			section:  "badges",
+			expected: "```\n<!--START_BADGES:badges-->\n<!--END_BADGES:badges-->\n```\n<!--START_BADGES:badges-->\nnew\n<!--END_BADGES:badges-->\n",
+		},
+
+		{
+			name:     "other sections are kept",
+			readme:   "<!--START_BADGES:cloud-->\n<!--END_BADGES:cloud-->\n<!--START_BADGES:security-->\n<!--END_BADGES:security-->\n",
+			section:  "security",
+			expected: "<!--START_BADGES:cloud-->\n<!--END_BADGES:cloud-->\n<!--START_BADGES:security-->\nnew\n<!--END_BADGES:security-->\n",
+		},
+
+		{
+			name:     "start marker with attributes",
+			readme:   "<!--START_BADGES:badges layout=table issuer=\"The Linux Foundation\"-->\nold\n<!--END_BADGES:badges-->\n",
+			section:  "badges",
+			expected: "<!--START_BADGES:badges layout=table issuer=\"The Linux Foundation\"-->\nnew\n<!--END_BADGES:badges-->\n",
+		},
+
+		{
+			name:     "section name prefix of another section",
+			readme:   "<!--START_BADGES:cloud-native-->\n<!--END_BADGES:cloud-native-->\n<!--START_BADGES:cloud-->\n<!--END_BADGES:cloud-->\n",
+			section:  "cloud",
+			expected: "<!--START_BADGES:cloud-native-->\n<!--END_BADGES:cloud-native-->\n<!--START_BADGES:cloud-->\nnew\n<!--END_BADGES:cloud-->\n",
+		},
+
+		{
+			name:    "missing start marker",
+			readme:  "<!--END_BADGES:badges-->\n",
+			section: "badges",
+			kind:    MissingStart,
+		},
+
+		{
+			name:    "missing end marker",
+			readme:  "<!--START_BADGES:badges-->\n",
+			section: "badges",
+			kind:    MissingEnd,
+		},
+
+		{
+			name:    "end marker only in code fence",
+			readme:  "<!--START_BADGES:badges-->\n~~~~\n<!--END_BADGES:badges-->\n~~~~\n",
+			section: "badges",
+			kind:    MissingEnd,
+		},
+
+		{
+			name:    "end before start",
+			readme:  "<!--END_BADGES:badges-->\n<!--START_BADGES:badges-->\n",
+			section: "badges",
+			kind:    EndBeforeStart,
+			line:    1,
+		},
+
+		{
+			name:    "duplicate start",
+			readme:  "<!--START_BADGES:badges-->\n<!--END_BADGES:badges-->\n<!--START_BADGES:badges-->\n",
+			section: "badges",
+			kind:    DuplicateStart,
+			line:    3,
+		},
+
+		{
+			name:    "duplicate end",
+			readme:  "<!--START_BADGES:badges-->\n<!--END_BADGES:badges-->\n\n<!--END_BADGES:badges-->\n",
+			section: "badges",
+			kind:    DuplicateEnd,
+			line:    4,
+		},
+
+		{
+			name:    "nested section",
+			readme:  "<!--START_BADGES:cloud-->\n<!--START_BADGES:security-->\n<!--END_BADGES:security-->\n<!--END_BADGES:cloud-->\n",
+			section: "cloud",
+			kind:    NestedSection,
+			line:    2,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			out, err := splice(tc.readme, sectionMarkers(tc.section), "new\n")
+			if tc.kind != 0 {
+				var markerErr *MarkerError
+				if !errors.As(err, &markerErr) {
+					t.Fatalf("expected marker error, got %v", err)
+				}
+
+				if markerErr.Kind != tc.kind {
+					t.Fatalf("expected %s, got %s", tc.kind, markerErr.Kind)
+				}
+
+				if tc.line != 0 && markerErr.Line != tc.line {
+					t.Fatalf("expected error on line %d, got %d", tc.line, markerErr.Line)
+				}
+
+				missing := tc.kind == MissingStart || tc.kind == MissingEnd
+				if errors.Is(err, ErrMarkersMissing) != missing {
+					t.Fatalf("expected errors.Is(err, ErrMarkersMissing) to be %t for %s", missing, tc.kind)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if out != tc.expected {
+				t.Fatalf("expected %q, got %q", tc.expected, out)
+			}
+		})
+	}
+}
+
+func TestSectionAttributes(t *testing.T) {
+	tt := []struct {
+		name     string
+		marker   string
+		expected SectionOptions
+		err      bool
+	}{
+		{
+			name:     "no attributes",
+			marker:   "<!--START_BADGES:badges-->",
+			expected: SectionOptions{},
+		},
+
+		{
+			name:   "attributes",
+			marker: `<!--START_BADGES:badges layout=table columns=4 sort=-issued issuer="The Linux Foundation" exclude-name='(?i)webinar' id=a,b-->`,
+			expected: SectionOptions{
+				Layout:  LayoutTable,
+				Columns: 4,
+				Sort:    "-issued",
+				Filter: credly.Filter{
+					IncludeIssuers: []string{"The Linux Foundation"},
+					ExcludeName:    "(?i)webinar",
+					IncludeIDs:     []string{"a", "b"},
+				},
+			},
+		},
+
+		{
+			name:   "unknown attribute",
+			marker: "<!--START_BADGES:badges colour=red-->",
+			err:    true,
+		},
+
+		{
+			name:   "attribute without value",
+			marker: "<!--START_BADGES:badges layout-->",
+			err:    true,
+		},
+
+		{
+			name:   "unterminated quote",
+			marker: `<!--START_BADGES:badges issuer="The Linux Foundation-->`,
+			err:    true,
+		},
+
+		{
+			name:   "invalid columns",
+			marker: "<!--START_BADGES:badges columns=many-->",
+			err:    true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			s, err := findSection(tc.marker+"\n<!--END_BADGES:badges-->\n", sectionMarkers("badges"))
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			attrs, err := s.attributes("badges")
+			if err == nil {
+				var options SectionOptions
+				options, err = attributeOptions(attrs)
+				if err == nil && !reflect.DeepEqual(options, tc.expected) {
+					t.Fatalf("expected %+v, got %+v", tc.expected, options)
+				}
+			}
+
+			if tc.err != (err != nil) {
+				t.Fatalf("expected error %t, got %v", tc.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package readme
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// MemoryStorage stores the readme and any other files in memory, e.g. to
+// test the badge pipeline without a repository.
+type MemoryStorage struct {
+	mu       sync.Mutex
+	fileName string
+	content  string
+	revision int
+	files    map[string]string
+	commits  []Commit
+}
+
+func NewMemoryStorage(content string) *MemoryStorage {
+	return &MemoryStorage{
+		fileName: "README.md",
+		content:  content,
+		revision: 1,
+		files:    make(map[string]string),
+	}
+}
+
+func (ms *MemoryStorage) WithFile(path, content string) *MemoryStorage {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	ms.files[path] = content
+	return ms
+}
+
+// Fetch returns the readme, its revision is incremented by every write.
+func (ms *MemoryStorage) Fetch(_ context.Context) (File, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	return File{Content: ms.content, Revision: strconv.Itoa(ms.revision)}, nil
+}
+
+func (ms *MemoryStorage) FetchFile(_ context.Context, path string) (string, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	content, ok := ms.files[path]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, path)
+	}
+
+	return content, nil
+}
+
+// Write replaces the readme, failing with ErrConflict if the readme was
+// written since the commit revision.
+func (ms *MemoryStorage) Write(_ context.Context, commit Commit) (string, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if commit.Revision != strconv.Itoa(ms.revision) {
+		return "", ErrConflict
+	}
+
+	ms.content = commit.Content
+	ms.revision++
+	ms.commits = append(ms.commits, commit)
+
+	return strconv.Itoa(ms.revision), nil
+}
+
+// Commits returns the commits written to the storage, oldest first.
+func (ms *MemoryStorage) Commits() []Commit {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	return append([]Commit(nil), ms.commits...)
+}
+
+func (ms *MemoryStorage) Filename() string {
+	return ms.fileName
+}
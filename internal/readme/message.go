@@ -0,0 +1,64 @@
+package readme
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// DefaultMessage is the commit message used when none is configured.
+const DefaultMessage = "Update Credly badges"
+
+var defaultMessage = &Message{tmpl: template.Must(template.New("message").Parse(DefaultMessage))}
+
+// MessageData is the data available to commit message templates.
+type MessageData struct {
+	Added   []string
+	Removed []string
+	// Badges is the number of badges rendered into the readme.
+	Badges int
+}
+
+// Identity is the name and email of a commit author or committer.
+type Identity struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// Message renders a commit message from a template, e.g.
+//
+//	Update Credly badges{{ with .Added }}, added {{ join ", " . }}{{ end }}
+type Message struct {
+	tmpl *template.Template
+}
+
+func NewMessage(text string) (*Message, error) {
+	tmpl, err := template.New("message").Funcs(TemplateFuncs()).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse commit message template: %w", err)
+	}
+
+	return &Message{tmpl: tmpl}, nil
+}
+
+// Render renders the commit message for the changes, trimming surrounding
+// whitespace. An empty message falls back to DefaultMessage.
+func (m *Message) Render(changes Changes, badges int) (string, error) {
+	var sb strings.Builder
+
+	err := m.tmpl.Execute(&sb, MessageData{
+		Added:   changes.Added,
+		Removed: changes.Removed,
+		Badges:  badges,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to render commit message: %w", err)
+	}
+
+	message := strings.TrimSpace(sb.String())
+	if message == "" {
+		return DefaultMessage, nil
+	}
+
+	return message, nil
+}
@@ -3,157 +3,242 @@ package readme
 import (
 	"context"
 	"errors"
-	"fmt"
 	"log"
-	"strings"
 
-	gh "github.com/google/go-github/v64/github"
 	"github.com/mikejoh/go-credly/internal/credly"
 )
 
 var ErrFilesAreEqual = errors.New("files are equal")
 
-type GitHubReadme struct {
-	githubClient *gh.Client
-	repoContent  *gh.RepositoryContent
-	readme       string
-	fileName     string
-	repo         string
-	owner        string
-	badgeStart   string
-	badgeEnd     string
-}
-
-func NewReadme(owner, repo string) *GitHubReadme {
-	fileName := "README.md"
-	badgeStart := "<!--START_BADGES:badges-->"
-	badgeEnd := "<!--END_BADGES:badges-->"
-
-	return &GitHubReadme{
-		githubClient: gh.NewClient(nil),
-		repoContent:  &gh.RepositoryContent{},
-		readme:       "",
-		fileName:     fileName,
-		repo:         repo,
-		owner:        owner,
-		badgeStart:   badgeStart,
-		badgeEnd:     badgeEnd,
-	}
+// defaultRetries is the number of times an update is retried after a
+// conflict.
+const defaultRetries = 3
+
+// Readme renders badges into a readme kept in a Storage.
+type Readme struct {
+	storage    Storage
+	file       File
+	readme     string
+	badgeStart string
+	badgeEnd   string
+	renderer   *Renderer
+	message    *Message
+	author     *Identity
+	committer  *Identity
+	badges     []credly.Badge
+	markers    []markers
+	apply      func(readme string) (string, error)
+	retries    int
 }
 
-func (gr *GitHubReadme) WithGitHubClient(client *gh.Client) *GitHubReadme {
-	gr.githubClient = client
-	return gr
+func New(storage Storage) *Readme {
+	return &Readme{
+		storage:    storage,
+		badgeStart: StartMarker(DefaultSection),
+		badgeEnd:   EndMarker(DefaultSection),
+		renderer:   DefaultRenderer(),
+		message:    defaultMessage,
+		retries:    defaultRetries,
+	}
 }
 
-func (gr *GitHubReadme) WithFileName(filename string) *GitHubReadme {
-	gr.fileName = filename
-	return gr
+func (r *Readme) WithBadgeStart(badgeStart string) *Readme {
+	r.badgeStart = badgeStart
+	return r
 }
 
-func (gr *GitHubReadme) WithBadgeStart(badgeStart string) *GitHubReadme {
-	gr.badgeStart = badgeStart
-	return gr
+func (r *Readme) WithBadgeEnd(badgeEnd string) *Readme {
+	r.badgeEnd = badgeEnd
+	return r
 }
 
-func (gr *GitHubReadme) WithBadgeEnd(badgeEnd string) *GitHubReadme {
-	gr.badgeEnd = badgeEnd
-	return gr
+func (r *Readme) WithRenderer(renderer *Renderer) *Readme {
+	r.renderer = renderer
+	return r
 }
 
-func (gr *GitHubReadme) Fetch(ctx context.Context) error {
-	content, _, _, err := gr.githubClient.Repositories.GetContents(ctx, gr.repo, gr.repo, gr.fileName, nil)
+// WithMessage sets the template of the commit message, see MessageData for
+// the available data.
+func (r *Readme) WithMessage(message string) (*Readme, error) {
+	m, err := NewMessage(message)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	readmeString, err := content.GetContent()
+	r.message = m
+	return r, nil
+}
+
+// WithRetries sets how many times Update refetches the readme and reapplies
+// the badges when the readme changed since it was fetched.
+func (r *Readme) WithRetries(retries int) *Readme {
+	r.retries = retries
+	return r
+}
+
+// WithAuthor sets the author of the commit, storages use their own default
+// when it is not set.
+func (r *Readme) WithAuthor(author Identity) *Readme {
+	r.author = &author
+	return r
+}
+
+// WithCommitter sets the committer of the commit, storages use their own
+// default when it is not set.
+func (r *Readme) WithCommitter(committer Identity) *Readme {
+	r.committer = &committer
+	return r
+}
+
+func (r *Readme) Fetch(ctx context.Context) error {
+	file, err := r.storage.Fetch(ctx)
 	if err != nil {
 		return err
 	}
 
-	gr.repoContent = content
-	gr.readme = readmeString
+	r.file = file
+	r.readme = file.Content
 
 	log.Println("readme content fetched and saved")
 
 	return nil
 }
 
-func (gr *GitHubReadme) WriteBadges(badges []credly.Badge) error {
-	originalReadme := gr.readme
-
-	var badgeMarkdown strings.Builder
-	for _, badge := range badges {
-		badgeMarkdown.WriteString(fmt.Sprintf("<img src=\"%s\" alt=\"%s\" />\n", badge.ImageSrc, badge.Alt))
-	}
+// FetchFile fetches the content of another file from the storage of the
+// readme, such as a badge template.
+func (r *Readme) FetchFile(ctx context.Context, path string) (string, error) {
+	return r.storage.FetchFile(ctx, path)
+}
 
-	startIndex, endIndex, err := findStartAndEndIndex(gr.readme, gr.badgeStart, gr.badgeEnd)
+func (r *Readme) WriteBadges(badges []credly.Badge) error {
+	badgeMarkdown, err := r.renderer.Render(badges)
 	if err != nil {
 		return err
 	}
 
-	gr.readme = gr.readme[:startIndex] + gr.badgeStart + "\n" + badgeMarkdown.String() + gr.badgeEnd + gr.readme[endIndex:]
-
-	if originalReadme == gr.readme {
-		return ErrFilesAreEqual
+	if r.badgeStart == "" || r.badgeEnd == "" {
+		return errors.New("badgeStart and badgeEnd cannot be empty")
 	}
 
-	return nil
+	m := literalMarkers(r.badgeStart, r.badgeEnd)
+
+	return r.write(badges, []markers{m}, func(readme string) (string, error) {
+		return splice(readme, m, badgeMarkdown)
+	})
 }
 
-func (gr *GitHubReadme) Get() string {
-	return gr.readme
-}
-
-func (gr *GitHubReadme) Update(ctx context.Context, branch string) error {
-	_, _, err := gr.githubClient.Repositories.UpdateFile(ctx, gr.repo, gr.owner, gr.Filename(), &gh.RepositoryContentFileOptions{
-		Branch:  gh.String(branch),
-		Message: gh.String("Update Credly badges"),
-		Committer: &gh.CommitAuthor{
-			Name:  gh.String("github-actions[bot]"),
-			Email: gh.String("41898282+github-actions[bot]@users.noreply.github.com"),
-		},
-		Author: &gh.CommitAuthor{
-			Name:  gh.String("github-actions[bot]"),
-			Email: gh.String("41898282+github-actions[bot]@users.noreply.github.com"),
-		},
-		Content: []byte(gr.readme),
-		SHA:     gr.repoContent.SHA,
+// WriteSections renders the badges into each of the provided sections, every
+// section is updated before the readme is written once.
+func (r *Readme) WriteSections(badges []credly.Badge, sections []Section) error {
+	m := make([]markers, 0, len(sections))
+	for _, section := range sections {
+		m = append(m, sectionMarkers(section.Name))
+	}
+
+	return r.write(badges, m, func(readme string) (string, error) {
+		return RenderSections(readme, badges, sections)
 	})
+}
+
+// write applies the badges to the sections of the readme delimited by the
+// markers, and keeps apply to reapply them if the readme has to be refetched.
+func (r *Readme) write(badges []credly.Badge, m []markers, apply func(readme string) (string, error)) error {
+	updated, err := apply(r.readme)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
-	log.Println("readme updated")
+	if updated == r.readme {
+		return ErrFilesAreEqual
+	}
+
+	r.readme = updated
+	r.badges = badges
+	r.markers = m
+	r.apply = apply
 
 	return nil
 }
 
-func (gr *GitHubReadme) Filename() string {
-	return gr.fileName
+// SectionNames returns the names of the badge sections in the readme, in
+// order of appearance.
+func (r *Readme) SectionNames() []string {
+	return SectionNames(r.readme)
 }
 
-func findStartAndEndIndex(readme, startString, endString string) (start, end int, err error) {
-	if startString == "" || endString == "" {
-		return 0, 0, errors.New("startString and endString cannot be empty")
-	}
+func (r *Readme) Get() string {
+	return r.readme
+}
+
+// Diff returns the unified diff between the fetched and the updated readme.
+func (r *Readme) Diff() string {
+	return UnifiedDiff("a/"+r.Filename(), "b/"+r.Filename(), r.file.Content, r.readme)
+}
 
-	if readme == "" {
-		return 0, 0, errors.New("readme cannot be empty")
+// Changes returns the badges added to and removed from the sections of the
+// fetched readme, text outside the sections is not compared.
+func (r *Readme) Changes() Changes {
+	return BadgeChanges(sectionContent(r.file.Content, r.markers), sectionContent(r.readme, r.markers), r.badges)
+}
+
+// Update writes the updated readme to the storage. If the readme changed
+// since it was fetched, it is refetched and the badges are reapplied, up to
+// the configured number of retries.
+func (r *Readme) Update(ctx context.Context) error {
+	for attempt := 1; ; attempt++ {
+		err := r.commit(ctx)
+		if !errors.Is(err, ErrConflict) || r.apply == nil || attempt > r.retries {
+			return err
+		}
+
+		log.Printf("%s changed since it was fetched, retrying (%d/%d)", r.Filename(), attempt, r.retries)
+
+		if err := r.Fetch(ctx); err != nil {
+			return err
+		}
+
+		updated, err := r.apply(r.readme)
+		if err != nil {
+			return err
+		}
+
+		if updated == r.readme {
+			log.Printf("%s is already up to date", r.Filename())
+			return nil
+		}
+
+		r.readme = updated
 	}
+}
 
-	startIndex := strings.Index(readme, startString)
-	if startIndex == -1 {
-		return 0, 0, fmt.Errorf("%s not found in readme", startString)
+// commit writes the readme to the storage, based on the fetched revision.
+func (r *Readme) commit(ctx context.Context) error {
+	changes := r.Changes()
+
+	message, err := r.message.Render(changes, len(r.badges))
+	if err != nil {
+		return err
 	}
 
-	endIndex := strings.Index(readme, endString)
-	if endIndex == -1 {
-		return 0, 0, fmt.Errorf("%s not found in readme", endString)
+	revision, err := r.storage.Write(ctx, Commit{
+		Content:   r.readme,
+		Revision:  r.file.Revision,
+		Message:   message,
+		Changes:   changes,
+		Author:    r.author,
+		Committer: r.committer,
+	})
+	if err != nil {
+		return err
 	}
 
-	endIndex += len(endString)
+	r.file = File{Content: r.readme, Revision: revision}
+
+	log.Println("readme updated")
+
+	return nil
+}
 
-	return startIndex, endIndex, nil
+func (r *Readme) Filename() string {
+	return r.storage.Filename()
 }
@@ -0,0 +1,200 @@
+package readme_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/mikejoh/go-credly/internal/credly"
+	"github.com/mikejoh/go-credly/internal/readme"
+)
+
+func TestReadmeMemoryStorage(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	storage := readme.NewMemoryStorage("# Hi\n<!--START_BADGES:certs-->\n<!--END_BADGES:certs-->\n").
+		WithFile(".github/badges.tmpl", "{{ range .Badges }}{{ .Name }}{{ \"\\n\" }}{{ end }}")
+
+	r, err := readme.New(storage).
+		WithAuthor(readme.Identity{Name: "Mike", Email: "mike@example.com"}).
+		WithMessage(`Add {{ len .Added }} of {{ .Badges }} badges{{ with .Added }}: {{ join ", " . }}{{ end }}`)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if err := r.Fetch(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	tmpl, err := r.FetchFile(ctx, ".github/badges.tmpl")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	sections := []readme.Section{{Name: "certs", Options: readme.SectionOptions{Template: tmpl, Sort: "name"}}}
+	if err := r.WriteSections(testBadges, sections); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if err := r.Update(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	file, err := storage.Fetch(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "# Hi\n<!--START_BADGES:certs-->\nCKA: Certified Kubernetes Administrator\nKCNA: Kubernetes and Cloud Native Associate\n<!--END_BADGES:certs-->\n"
+	if file.Content != expected {
+		t.Fatalf("expected %q, got %q", expected, file.Content)
+	}
+
+	commits := storage.Commits()
+	if len(commits) != 1 {
+		t.Fatalf("expected one commit, got %d", len(commits))
+	}
+
+	message := "Add 2 of 2 badges: CKA: Certified Kubernetes Administrator, KCNA: Kubernetes and Cloud Native Associate"
+	if commits[0].Message != message {
+		t.Fatalf("expected message %q, got %q", message, commits[0].Message)
+	}
+
+	if commits[0].Author == nil || commits[0].Author.Email != "mike@example.com" || commits[0].Committer != nil {
+		t.Fatalf("expected the author to be set, got %+v and %+v", commits[0].Author, commits[0].Committer)
+	}
+
+	stale := readme.New(storage)
+	if err := stale.Fetch(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Update(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if err := stale.Update(ctx); !errors.Is(err, readme.ErrConflict) {
+		t.Fatalf("expected %v, got %v", readme.ErrConflict, err)
+	}
+}
+
+func TestReadmeChanges(t *testing.T) {
+	prose := "I hold the CKA: Certified Kubernetes Administrator and the KCNA: Kubernetes and Cloud Native Associate.\n"
+	section := func(content string) string {
+		return prose + "<!--START_BADGES:badges-->\n" + content + "<!--END_BADGES:badges-->\n"
+	}
+
+	list, err := readme.NewLayoutRenderer(readme.LayoutList)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cka, err := list.Render(testBadges[:1])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tt := []struct {
+		name     string
+		readme   string
+		badges   []credly.Badge
+		expected readme.Changes
+	}{
+		{
+			name:     "added",
+			readme:   section(""),
+			badges:   testBadges[:1],
+			expected: readme.Changes{Added: []string{"CKA: Certified Kubernetes Administrator"}},
+		},
+		{
+			name:   "removed",
+			readme: section(cka),
+			badges: testBadges[1:],
+			expected: readme.Changes{
+				Added:   []string{"KCNA: Kubernetes and Cloud Native Associate"},
+				Removed: []string{"CKA: Certified Kubernetes Administrator"},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := readme.New(readme.NewMemoryStorage(tc.readme))
+			if err := r.Fetch(context.Background()); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			sections := []readme.Section{{Name: readme.DefaultSection, Options: readme.SectionOptions{Layout: readme.LayoutList}}}
+			if err := r.WriteSections(tc.badges, sections); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if changes := r.Changes(); !reflect.DeepEqual(changes, tc.expected) {
+				t.Fatalf("expected %+v, got %+v", tc.expected, changes)
+			}
+		})
+	}
+}
+
+func TestMessage(t *testing.T) {
+	tt := []struct {
+		name     string
+		template string
+		changes  readme.Changes
+		expected string
+		err      bool
+	}{
+		{
+			name:     "counts",
+			template: "Update Credly badges (+{{ len .Added }} -{{ len .Removed }})",
+			changes:  readme.Changes{Added: []string{"CKA"}, Removed: []string{"CKAD", "CKS"}},
+			expected: "Update Credly badges (+1 -2)",
+		},
+		{
+			name:     "names",
+			template: "Update Credly badges\n\n{{ range .Added }}Added {{ . }}\n{{ end }}{{ range .Removed }}Removed {{ . }}\n{{ end }}",
+			changes:  readme.Changes{Added: []string{"CKA"}, Removed: []string{"CKS"}},
+			expected: "Update Credly badges\n\nAdded CKA\nRemoved CKS",
+		},
+		{
+			name:     "empty",
+			template: "{{ with .Added }}Add {{ join \", \" . }}{{ end }}",
+			expected: readme.DefaultMessage,
+		},
+		{
+			name:     "invalid",
+			template: "{{ .Added",
+			err:      true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			m, err := readme.NewMessage(tc.template)
+			if tc.err {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			message, err := m.Render(tc.changes, 3)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if message != tc.expected {
+				t.Fatalf("expected %q, got %q", tc.expected, message)
+			}
+		})
+	}
+}
@@ -0,0 +1,367 @@
+package readme
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/mikejoh/go-credly/internal/credly"
+)
+
+// Built-in layouts selectable by name.
+const (
+	LayoutImages = "images"
+	LayoutTable  = "table"
+	LayoutList   = "list"
+	LayoutIcons  = "icons"
+	LayoutCards  = "cards"
+)
+
+// Policies for rendering expired badges.
+const (
+	ExpiredShow     = "show"
+	ExpiredHide     = "hide"
+	ExpiredMark     = "mark"
+	ExpiredSeparate = "separate-section"
+)
+
+const defaultColumns = 4
+
+// pastHeading introduces the expired badges rendered in a separate section.
+const pastHeading = "**Past certifications**"
+
+// layouts holds the templates of the built-in layouts.
+var layouts = map[string]string{
+	// A linked image per line.
+	LayoutImages: `{{ range .Badges }}{{ badgeImage . $.Size }}{{ if and $.MarkExpired (expired .) }} <sub>(expired)</sub>{{ end }}
+{{ end }}`,
+
+	// A Markdown table with the badge image and name in each cell.
+	LayoutTable: `{{ with chunk .Columns .Badges }}|{{ range index . 0 }} |{{ end }}
+|{{ range index . 0 }}:---:|{{ end }}
+{{ range . }}|{{ range . }} {{ badgeImage . $.Size }}<br />{{ cell .Name }}{{ if and $.MarkExpired (expired .) }}<br /><sub>(expired)</sub>{{ end }} |{{ end }}
+{{ end }}{{ end }}`,
+
+	// A bullet list with name, issuer and issue date.
+	LayoutList: `{{ range .Badges }}- {{ if .URL }}[{{ .Name }}]({{ .URL }}){{ else }}{{ .Name }}{{ end }}{{ with .Issuer }}, {{ . }}{{ end }}{{ with date "2006-01-02" .IssuedAt }} ({{ . }}){{ end }}{{ if and $.MarkExpired (expired .) }}, expired {{ date "2006-01-02" .ExpiresAt }}{{ end }}
+{{ end }}`,
+
+	// A single row of small icons.
+	LayoutIcons: `{{ range $i, $b := .Badges }}{{ if $i }} {{ end }}{{ badgeImage $b 32 }}{{ end }}`,
+
+	// A card per badge with description and skills.
+	LayoutCards: `{{ range $b := .Badges }}<table>
+<tr>
+<td>{{ badgeImage . $.Size }}</td>
+<td>
+<strong>{{ escape .Name }}</strong><br />
+{{ escape .Issuer }}{{ with .Level }} · {{ escape . }}{{ end }}{{ with date "Jan 2006" .IssuedAt }} · Issued {{ . }}{{ end }}{{ with date "Jan 2006" .ExpiresAt }} · {{ if and $.MarkExpired (expired $b) }}<b>Expired</b>{{ else }}Expires{{ end }} {{ . }}{{ end }}
+{{- with .Description }}<br />
+<sub>{{ escape (truncate 280 .) }}</sub>{{ end }}
+{{- with .Skills }}<br />
+<sub><b>Skills:</b> {{ escape (join ", " .) }}</sub>{{ end }}
+</td>
+</tr>
+</table>
+{{ end }}`,
+}
+
+// TemplateData is the data available to badge templates.
+type TemplateData struct {
+	Badges      []credly.Badge
+	Columns     int
+	Size        int
+	MarkExpired bool
+}
+
+// Group is a set of badges sharing the same key, as returned by the groupBy
+// template function.
+type Group struct {
+	Key    string
+	Badges []credly.Badge
+}
+
+// Renderer renders badges into the content placed between the badge markers.
+type Renderer struct {
+	tmpl    *template.Template
+	columns int
+	size    int
+	expired string
+	now     func() time.Time
+}
+
+// NewRenderer creates a Renderer from the provided text/template source. The
+// template is executed with TemplateData and has access to the functions
+// returned by TemplateFuncs.
+func NewRenderer(text string) (*Renderer, error) {
+	tmpl, err := template.New("badges").Funcs(TemplateFuncs()).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse badge template: %w", err)
+	}
+
+	return &Renderer{
+		tmpl:    tmpl,
+		columns: defaultColumns,
+		expired: ExpiredShow,
+		now:     time.Now,
+	}, nil
+}
+
+// NewLayoutRenderer creates a Renderer for one of the built-in layouts.
+func NewLayoutRenderer(layout string) (*Renderer, error) {
+	text, ok := layouts[layout]
+	if !ok {
+		return nil, fmt.Errorf("unknown layout %q, expected one of %s", layout, strings.Join(Layouts(), ", "))
+	}
+
+	return NewRenderer(text)
+}
+
+// DefaultRenderer returns the Renderer used when no template is provided.
+func DefaultRenderer() *Renderer {
+	r, err := NewLayoutRenderer(LayoutImages)
+	if err != nil {
+		panic(err)
+	}
+
+	return r
+}
+
+// Layouts returns the names of the built-in layouts.
+func Layouts() []string {
+	return []string{LayoutImages, LayoutTable, LayoutList, LayoutIcons, LayoutCards}
+}
+
+// WithColumns sets the number of columns used by table layouts.
+func (r *Renderer) WithColumns(columns int) *Renderer {
+	if columns > 0 {
+		r.columns = columns
+	}
+	return r
+}
+
+// WithSize sets the badge image size in pixels, or credly.OriginalSize for
+// the original images. Image URLs are left as extracted when no size is set.
+func (r *Renderer) WithSize(size int) *Renderer {
+	r.size = size
+	return r
+}
+
+// WithExpired sets the policy for rendering expired badges, one of
+// ExpiredShow, ExpiredHide, ExpiredMark or ExpiredSeparate.
+func (r *Renderer) WithExpired(policy string) (*Renderer, error) {
+	switch policy {
+	case ExpiredShow, ExpiredHide, ExpiredMark, ExpiredSeparate:
+		r.expired = policy
+	case "":
+		r.expired = ExpiredShow
+	default:
+		return nil, fmt.Errorf("unknown expired policy %q, expected one of %s, %s, %s or %s", policy, ExpiredShow, ExpiredHide, ExpiredMark, ExpiredSeparate)
+	}
+	return r, nil
+}
+
+// Render renders the provided badges.
+func (r *Renderer) Render(badges []credly.Badge) (string, error) {
+	now := r.now()
+
+	var current, past []credly.Badge
+	for _, badge := range badges {
+		badge.ImageSrc = credly.ResizeImageURL(badge.ImageSrc, r.size)
+
+		if !badge.Expired(now) {
+			current = append(current, badge)
+			continue
+		}
+
+		switch r.expired {
+		case ExpiredHide:
+		case ExpiredSeparate:
+			past = append(past, badge)
+		case ExpiredMark:
+			if badge.Alt == "" {
+				badge.Alt = badge.Name
+			}
+			badge.Alt += " (expired)"
+			current = append(current, badge)
+		default:
+			current = append(current, badge)
+		}
+	}
+
+	out, err := r.execute(current, now)
+	if err != nil {
+		return "", err
+	}
+
+	if len(past) > 0 {
+		pastOut, err := r.execute(past, now)
+		if err != nil {
+			return "", err
+		}
+
+		if out != "" {
+			out += "\n"
+		}
+		out += pastHeading + "\n\n" + pastOut
+	}
+
+	return out, nil
+}
+
+func (r *Renderer) execute(badges []credly.Badge, now time.Time) (string, error) {
+	// Clone the template to bind the expired function to the render time
+	// without affecting concurrent renders.
+	tmpl, err := r.tmpl.Clone()
+	if err != nil {
+		return "", err
+	}
+
+	tmpl.Funcs(template.FuncMap{
+		"expired": func(b credly.Badge) bool { return b.Expired(now) },
+	})
+
+	data := TemplateData{
+		Badges:      badges,
+		Columns:     r.columns,
+		Size:        max(r.size, 0),
+		MarkExpired: r.expired == ExpiredMark,
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("failed to render badge template: %w", err)
+	}
+
+	out := sb.String()
+	if out != "" && !strings.HasSuffix(out, "\n") {
+		out += "\n"
+	}
+
+	return out, nil
+}
+
+// TemplateFuncs returns the helper functions available to badge templates:
+//
+//	badgeImage BADGE [SIZE]  linked <img> tag for the badge, SIZE pixels wide
+//	date LAYOUT TIME         formatted time, empty for the zero time
+//	truncate N STRING        string cut to N characters with an ellipsis
+//	groupBy FIELD BADGES     badges grouped by issuer, level, category or year
+//	chunk N BADGES           badges split into rows of N
+//	join SEP LIST            strings joined by SEP
+//	escape STRING            HTML escaped string
+//	cell STRING              HTML escaped string safe to use in a table cell
+//	expired BADGE            whether the badge has expired
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"badgeImage": badgeHTML,
+		"date":       formatDate,
+		"truncate":   truncate,
+		"groupBy":    groupBy,
+		"chunk":      chunk,
+		"join":       func(sep string, elems []string) string { return strings.Join(elems, sep) },
+		"escape":     html.EscapeString,
+		"cell":       tableCell,
+		"expired":    func(b credly.Badge) bool { return b.Expired(time.Now()) },
+	}
+}
+
+// badgeHTML renders a badge image, linked to its public verification page
+// when the badge URL is known. An optional size sets the image width and
+// height in pixels, and fetches the image from Credly in that size.
+func badgeHTML(badge credly.Badge, size ...int) string {
+	alt := badge.Alt
+	if alt == "" {
+		alt = badge.Name
+	}
+
+	var dimensions string
+	if len(size) > 0 && size[0] > 0 {
+		dimensions = fmt.Sprintf(" width=\"%d\" height=\"%d\"", size[0], size[0])
+		badge.ImageSrc = credly.ResizeImageURL(badge.ImageSrc, size[0])
+	}
+
+	img := fmt.Sprintf("<img src=\"%s\" alt=\"%s\" title=\"%s\"%s />", html.EscapeString(badge.ImageSrc), html.EscapeString(alt), html.EscapeString(alt), dimensions)
+	if badge.URL == "" {
+		return img
+	}
+
+	return fmt.Sprintf("<a href=\"%s\">%s</a>", html.EscapeString(badge.URL), img)
+}
+
+func tableCell(s string) string {
+	return strings.ReplaceAll(html.EscapeString(s), "|", "&#124;")
+}
+
+func formatDate(layout string, t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.Format(layout)
+}
+
+func truncate(n int, s string) string {
+	runes := []rune(s)
+	if n <= 0 || len(runes) <= n {
+		return s
+	}
+
+	if n == 1 {
+		return "…"
+	}
+
+	return string(runes[:n-1]) + "…"
+}
+
+func groupBy(field string, badges []credly.Badge) ([]Group, error) {
+	var key func(credly.Badge) string
+
+	switch field {
+	case "issuer":
+		key = func(b credly.Badge) string { return b.Issuer }
+	case "level":
+		key = func(b credly.Badge) string { return b.Level }
+	case "category":
+		key = func(b credly.Badge) string { return b.TypeCategory }
+	case "year":
+		key = func(b credly.Badge) string { return formatDate("2006", b.IssuedAt) }
+	default:
+		return nil, fmt.Errorf("unknown group field %q, expected one of issuer, level, category or year", field)
+	}
+
+	var groups []Group
+
+	index := make(map[string]int)
+	for _, badge := range badges {
+		k := key(badge)
+		i, ok := index[k]
+		if !ok {
+			i = len(groups)
+			index[k] = i
+			groups = append(groups, Group{Key: k})
+		}
+		groups[i].Badges = append(groups[i].Badges, badge)
+	}
+
+	return groups, nil
+}
+
+func chunk(n int, badges []credly.Badge) [][]credly.Badge {
+	if n <= 0 {
+		n = 1
+	}
+
+	var rows [][]credly.Badge
+	for len(badges) > n {
+		rows = append(rows, badges[:n])
+		badges = badges[n:]
+	}
+	if len(badges) > 0 {
+		rows = append(rows, badges)
+	}
+
+	return rows
+}
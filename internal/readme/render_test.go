@@ -0,0 +1,260 @@
+package readme_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mikejoh/go-credly/internal/credly"
+	"github.com/mikejoh/go-credly/internal/readme"
+)
+
+var testBadges = []credly.Badge{
+	{
+		ID:           "20f4aaea-770e-4e32-8cd0-f2720fb11d85",
+		Name:         "CKA: Certified Kubernetes Administrator",
+		ImageSrc:     "https://images.credly.com/size/110x110/images/8b8ed108-e77d-4396-ac59-2504583b9d54/cka_from_cncfsite__281_29.png",
+		URL:          "https://www.credly.com/badges/20f4aaea-770e-4e32-8cd0-f2720fb11d85",
+		Issuer:       "The Linux Foundation",
+		IssuedAt:     time.Date(2023, time.March, 14, 0, 0, 0, 0, time.UTC),
+		ExpiresAt:    time.Date(2026, time.March, 14, 0, 0, 0, 0, time.UTC),
+		Level:        "Intermediate",
+		TypeCategory: "Certification",
+	},
+	{
+		ID:           "062ae104-f532-43d0-b3bd-b6599dd03e2c",
+		Name:         "KCNA: Kubernetes and Cloud Native Associate",
+		ImageSrc:     "https://images.credly.com/size/110x110/images/f28f1d88-428a-47f6-95b5-7da1dd6c1000/KCNA_badge.png",
+		URL:          "https://www.credly.com/badges/062ae104-f532-43d0-b3bd-b6599dd03e2c",
+		Issuer:       "The Linux Foundation",
+		IssuedAt:     time.Date(2024, time.August, 29, 0, 0, 0, 0, time.UTC),
+		ExpiresAt:    time.Date(2026, time.August, 30, 0, 0, 0, 0, time.UTC),
+		Level:        "Foundational",
+		TypeCategory: "Certification",
+	},
+}
+
+func TestRender(t *testing.T) {
+	tt := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{
+			name: "default template",
+			expected: `<a href="https://www.credly.com/badges/20f4aaea-770e-4e32-8cd0-f2720fb11d85"><img src="https://images.credly.com/size/110x110/images/8b8ed108-e77d-4396-ac59-2504583b9d54/cka_from_cncfsite__281_29.png" alt="CKA: Certified Kubernetes Administrator" title="CKA: Certified Kubernetes Administrator" /></a>
+<a href="https://www.credly.com/badges/062ae104-f532-43d0-b3bd-b6599dd03e2c"><img src="https://images.credly.com/size/110x110/images/f28f1d88-428a-47f6-95b5-7da1dd6c1000/KCNA_badge.png" alt="KCNA: Kubernetes and Cloud Native Associate" title="KCNA: Kubernetes and Cloud Native Associate" /></a>
+`,
+		},
+
+		{
+			name:     "custom list template",
+			template: `{{ range .Badges }}- {{ truncate 5 .Name }} ({{ date "2006-01-02" .IssuedAt }}){{ "\n" }}{{ end }}`,
+			expected: "- CKA:… (2023-03-14)\n- KCNA… (2024-08-29)\n",
+		},
+
+		{
+			name:     "grouped template",
+			template: `{{ range groupBy "issuer" .Badges }}{{ .Key }}: {{ len .Badges }}{{ end }}`,
+			expected: "The Linux Foundation: 2\n",
+		},
+
+		{
+			name:     "chunked template",
+			template: `{{ range chunk 1 .Badges }}|{{ range . }} {{ .Level }} |{{ end }}{{ "\n" }}{{ end }}`,
+			expected: "| Intermediate |\n| Foundational |\n",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			renderer := readme.DefaultRenderer()
+			if tc.template != "" {
+				var err error
+				renderer, err = readme.NewRenderer(tc.template)
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+			}
+
+			out, err := renderer.Render(testBadges)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if out != tc.expected {
+				t.Fatalf("expected %q, got %q", tc.expected, out)
+			}
+		})
+	}
+}
+
+func TestNewRendererInvalidTemplate(t *testing.T) {
+	t.Parallel()
+
+	if _, err := readme.NewRenderer("{{ range .Badges }}"); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestLayoutRenderer(t *testing.T) {
+	tt := []struct {
+		name     string
+		layout   string
+		columns  int
+		size     int
+		expected string
+		err      bool
+	}{
+		{
+			name:    "table",
+			layout:  readme.LayoutTable,
+			columns: 1,
+			expected: `| |
+|:---:|
+| <a href="https://www.credly.com/badges/20f4aaea-770e-4e32-8cd0-f2720fb11d85"><img src="https://images.credly.com/size/110x110/images/8b8ed108-e77d-4396-ac59-2504583b9d54/cka_from_cncfsite__281_29.png" alt="CKA: Certified Kubernetes Administrator" title="CKA: Certified Kubernetes Administrator" /></a><br />CKA: Certified Kubernetes Administrator |
+| <a href="https://www.credly.com/badges/062ae104-f532-43d0-b3bd-b6599dd03e2c"><img src="https://images.credly.com/size/110x110/images/f28f1d88-428a-47f6-95b5-7da1dd6c1000/KCNA_badge.png" alt="KCNA: Kubernetes and Cloud Native Associate" title="KCNA: Kubernetes and Cloud Native Associate" /></a><br />KCNA: Kubernetes and Cloud Native Associate |
+`,
+		},
+
+		{
+			name:   "list",
+			layout: readme.LayoutList,
+			expected: `- [CKA: Certified Kubernetes Administrator](https://www.credly.com/badges/20f4aaea-770e-4e32-8cd0-f2720fb11d85), The Linux Foundation (2023-03-14)
+- [KCNA: Kubernetes and Cloud Native Associate](https://www.credly.com/badges/062ae104-f532-43d0-b3bd-b6599dd03e2c), The Linux Foundation (2024-08-29)
+`,
+		},
+
+		{
+			name:   "icons",
+			layout: readme.LayoutIcons,
+			expected: `<a href="https://www.credly.com/badges/20f4aaea-770e-4e32-8cd0-f2720fb11d85"><img src="https://images.credly.com/size/32x32/images/8b8ed108-e77d-4396-ac59-2504583b9d54/cka_from_cncfsite__281_29.png" alt="CKA: Certified Kubernetes Administrator" title="CKA: Certified Kubernetes Administrator" width="32" height="32" /></a> <a href="https://www.credly.com/badges/062ae104-f532-43d0-b3bd-b6599dd03e2c"><img src="https://images.credly.com/size/32x32/images/f28f1d88-428a-47f6-95b5-7da1dd6c1000/KCNA_badge.png" alt="KCNA: Kubernetes and Cloud Native Associate" title="KCNA: Kubernetes and Cloud Native Associate" width="32" height="32" /></a>
+`,
+		},
+
+		{
+			name:   "icons with size",
+			layout: readme.LayoutIcons,
+			size:   110,
+			expected: `<a href="https://www.credly.com/badges/20f4aaea-770e-4e32-8cd0-f2720fb11d85"><img src="https://images.credly.com/size/32x32/images/8b8ed108-e77d-4396-ac59-2504583b9d54/cka_from_cncfsite__281_29.png" alt="CKA: Certified Kubernetes Administrator" title="CKA: Certified Kubernetes Administrator" width="32" height="32" /></a> <a href="https://www.credly.com/badges/062ae104-f532-43d0-b3bd-b6599dd03e2c"><img src="https://images.credly.com/size/32x32/images/f28f1d88-428a-47f6-95b5-7da1dd6c1000/KCNA_badge.png" alt="KCNA: Kubernetes and Cloud Native Associate" title="KCNA: Kubernetes and Cloud Native Associate" width="32" height="32" /></a>
+`,
+		},
+
+		{
+			name:   "images with size",
+			layout: readme.LayoutImages,
+			size:   64,
+			expected: `<a href="https://www.credly.com/badges/20f4aaea-770e-4e32-8cd0-f2720fb11d85"><img src="https://images.credly.com/size/64x64/images/8b8ed108-e77d-4396-ac59-2504583b9d54/cka_from_cncfsite__281_29.png" alt="CKA: Certified Kubernetes Administrator" title="CKA: Certified Kubernetes Administrator" width="64" height="64" /></a>
+<a href="https://www.credly.com/badges/062ae104-f532-43d0-b3bd-b6599dd03e2c"><img src="https://images.credly.com/size/64x64/images/f28f1d88-428a-47f6-95b5-7da1dd6c1000/KCNA_badge.png" alt="KCNA: Kubernetes and Cloud Native Associate" title="KCNA: Kubernetes and Cloud Native Associate" width="64" height="64" /></a>
+`,
+		},
+
+		{
+			name:   "unknown layout",
+			layout: "carousel",
+			err:    true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			renderer, err := readme.NewLayoutRenderer(tc.layout)
+			if tc.err {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			out, err := renderer.WithColumns(tc.columns).WithSize(tc.size).Render(testBadges)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if out != tc.expected {
+				t.Fatalf("expected %q, got %q", tc.expected, out)
+			}
+		})
+	}
+}
+
+func TestRenderExpired(t *testing.T) {
+	badges := []credly.Badge{
+		{Name: "Current", IssuedAt: time.Date(2024, time.August, 29, 0, 0, 0, 0, time.UTC)},
+		{Name: "Old", IssuedAt: time.Date(1999, time.March, 14, 0, 0, 0, 0, time.UTC), ExpiresAt: time.Date(2001, time.March, 14, 0, 0, 0, 0, time.UTC)},
+	}
+
+	tt := []struct {
+		name     string
+		policy   string
+		expected string
+		err      bool
+	}{
+		{
+			name:     "show",
+			policy:   readme.ExpiredShow,
+			expected: "- Current (2024-08-29)\n- Old (1999-03-14)\n",
+		},
+
+		{
+			name:     "hide",
+			policy:   readme.ExpiredHide,
+			expected: "- Current (2024-08-29)\n",
+		},
+
+		{
+			name:     "mark",
+			policy:   readme.ExpiredMark,
+			expected: "- Current (2024-08-29)\n- Old (1999-03-14), expired 2001-03-14\n",
+		},
+
+		{
+			name:     "separate section",
+			policy:   readme.ExpiredSeparate,
+			expected: "- Current (2024-08-29)\n\n**Past certifications**\n\n- Old (1999-03-14)\n",
+		},
+
+		{
+			name:   "unknown policy",
+			policy: "archive",
+			err:    true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			renderer, err := readme.NewLayoutRenderer(readme.LayoutList)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			renderer, err = renderer.WithExpired(tc.policy)
+			if tc.err {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			out, err := renderer.Render(badges)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if out != tc.expected {
+				t.Fatalf("expected %q, got %q", tc.expected, out)
+			}
+		})
+	}
+}
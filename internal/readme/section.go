@@ -0,0 +1,239 @@
+package readme
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mikejoh/go-credly/internal/credly"
+)
+
+// DefaultSection is the name of the section delimited by the default badge
+// markers.
+const DefaultSection = "badges"
+
+// Section is a named badge section of the readme, delimited by the
+// <!--START_BADGES:name--> and <!--END_BADGES:name--> markers.
+type Section struct {
+	Name    string
+	Options SectionOptions
+}
+
+// SectionOptions configures how the badges of a section are selected and
+// rendered. Unset options use the renderer defaults.
+type SectionOptions struct {
+	Layout   string        `json:"layout,omitempty"`
+	Template string        `json:"template,omitempty"`
+	Columns  int           `json:"columns,omitempty"`
+	Size     string        `json:"size,omitempty"`
+	Sort     string        `json:"sort,omitempty"`
+	Expired  string        `json:"expired,omitempty"`
+	Filter   credly.Filter `json:"filter"`
+}
+
+// StartMarker returns the marker starting the named section.
+func StartMarker(name string) string {
+	return "<!--START_BADGES:" + name + "-->"
+}
+
+// EndMarker returns the marker ending the named section.
+func EndMarker(name string) string {
+	return "<!--END_BADGES:" + name + "-->"
+}
+
+// Merge returns the options with every option set in override replacing the
+// current value. Filter lists and patterns are replaced individually, and a
+// layout in override replaces the current template.
+func (o SectionOptions) Merge(override SectionOptions) SectionOptions {
+	str := func(dst *string, src string) {
+		if src != "" {
+			*dst = src
+		}
+	}
+	list := func(dst *[]string, src []string) {
+		if len(src) > 0 {
+			*dst = src
+		}
+	}
+
+	if override.Layout != "" && override.Template == "" {
+		o.Template = ""
+	}
+	str(&o.Layout, override.Layout)
+	str(&o.Template, override.Template)
+	str(&o.Size, override.Size)
+	str(&o.Sort, override.Sort)
+	str(&o.Expired, override.Expired)
+	if override.Columns > 0 {
+		o.Columns = override.Columns
+	}
+
+	f, of := &o.Filter, override.Filter
+	list(&f.IncludeIssuers, of.IncludeIssuers)
+	list(&f.ExcludeIssuers, of.ExcludeIssuers)
+	list(&f.IncludeCategories, of.IncludeCategories)
+	list(&f.ExcludeCategories, of.ExcludeCategories)
+	list(&f.IncludeLevels, of.IncludeLevels)
+	list(&f.ExcludeLevels, of.ExcludeLevels)
+	str(&f.IncludeName, of.IncludeName)
+	str(&f.ExcludeName, of.ExcludeName)
+	list(&f.IncludeIDs, of.IncludeIDs)
+	list(&f.ExcludeIDs, of.ExcludeIDs)
+
+	return o
+}
+
+// NewRenderer creates the Renderer described by the options. A template
+// takes precedence over the layout.
+func (o SectionOptions) NewRenderer() (*Renderer, error) {
+	var (
+		renderer *Renderer
+		err      error
+	)
+
+	switch {
+	case o.Template != "":
+		renderer, err = NewRenderer(o.Template)
+	case o.Layout != "":
+		renderer, err = NewLayoutRenderer(o.Layout)
+	default:
+		renderer = DefaultRenderer()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	renderer.WithColumns(o.Columns)
+
+	if o.Size != "" {
+		size, err := credly.ParseImageSize(o.Size)
+		if err != nil {
+			return nil, err
+		}
+		renderer.WithSize(size)
+	}
+
+	return renderer.WithExpired(o.Expired)
+}
+
+// attributeOptions returns the section options configured by the attributes
+// of a start marker, e.g.
+//
+//	<!--START_BADGES:badges layout=table columns=4 sort=-issued issuer="The Linux Foundation"-->
+func attributeOptions(attrs map[string]string) (SectionOptions, error) {
+	var o SectionOptions
+
+	list := func(v string) []string {
+		var l []string
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				l = append(l, s)
+			}
+		}
+		return l
+	}
+
+	for key, value := range attrs {
+		switch key {
+		case "layout":
+			o.Layout = value
+		case "columns":
+			columns, err := strconv.Atoi(value)
+			if err != nil || columns <= 0 {
+				return o, fmt.Errorf("invalid columns %q", value)
+			}
+			o.Columns = columns
+		case "size":
+			o.Size = value
+		case "sort":
+			o.Sort = value
+		case "expired":
+			o.Expired = value
+		case "issuer":
+			o.Filter.IncludeIssuers = list(value)
+		case "exclude-issuer":
+			o.Filter.ExcludeIssuers = list(value)
+		case "category":
+			o.Filter.IncludeCategories = list(value)
+		case "exclude-category":
+			o.Filter.ExcludeCategories = list(value)
+		case "level":
+			o.Filter.IncludeLevels = list(value)
+		case "exclude-level":
+			o.Filter.ExcludeLevels = list(value)
+		case "name":
+			o.Filter.IncludeName = value
+		case "exclude-name":
+			o.Filter.ExcludeName = value
+		case "id":
+			o.Filter.IncludeIDs = list(value)
+		case "exclude-id":
+			o.Filter.ExcludeIDs = list(value)
+		default:
+			return o, fmt.Errorf("unknown attribute %q", key)
+		}
+	}
+
+	return o, nil
+}
+
+// Render filters, sorts and renders the badges of the section.
+func (s Section) Render(badges []credly.Badge) (string, error) {
+	renderer, err := s.Options.NewRenderer()
+	if err != nil {
+		return "", fmt.Errorf("section %s: %w", s.Name, err)
+	}
+
+	order, err := credly.ParseSortOrder(s.Options.Sort)
+	if err != nil {
+		return "", fmt.Errorf("section %s: %w", s.Name, err)
+	}
+
+	filtered, err := s.Options.Filter.Apply(badges)
+	if err != nil {
+		return "", fmt.Errorf("section %s: %w", s.Name, err)
+	}
+
+	sorted := slices.Clone(filtered)
+	order.Sort(sorted)
+
+	content, err := renderer.Render(sorted)
+	if err != nil {
+		return "", fmt.Errorf("section %s: %w", s.Name, err)
+	}
+
+	return content, nil
+}
+
+// RenderSections renders the badges into each of the provided sections of
+// the readme and returns the updated readme. Attributes of a start marker
+// take precedence over the options of its section.
+func RenderSections(readme string, badges []credly.Badge, sections []Section) (string, error) {
+	for _, section := range sections {
+		s, err := findSection(readme, sectionMarkers(section.Name))
+		if err != nil {
+			return "", err
+		}
+
+		attrs, err := s.attributes(section.Name)
+		if err != nil {
+			return "", err
+		}
+
+		options, err := attributeOptions(attrs)
+		if err != nil {
+			return "", &MarkerError{Kind: InvalidAttributes, Section: section.Name, Line: s.start.line, Err: err}
+		}
+		section.Options = section.Options.Merge(options)
+
+		content, err := section.Render(badges)
+		if err != nil {
+			return "", err
+		}
+
+		readme = s.replace(readme, content)
+	}
+
+	return readme, nil
+}
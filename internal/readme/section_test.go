@@ -0,0 +1,101 @@
+package readme_test
+
+import (
+	"testing"
+
+	"github.com/mikejoh/go-credly/internal/credly"
+	"github.com/mikejoh/go-credly/internal/readme"
+)
+
+func TestSectionOptionsMerge(t *testing.T) {
+	t.Parallel()
+
+	base := readme.SectionOptions{
+		Template: "{{ len .Badges }}",
+		Columns:  4,
+		Size:     "110",
+		Filter:   credly.Filter{IncludeCategories: []string{"Certification"}},
+	}
+
+	merged := base.Merge(readme.SectionOptions{
+		Layout: readme.LayoutTable,
+		Sort:   "-issued",
+		Filter: credly.Filter{IncludeIssuers: []string{"The Linux Foundation"}},
+	})
+
+	if merged.Layout != readme.LayoutTable || merged.Template != "" {
+		t.Fatalf("expected layout to replace template, got layout %q and template %q", merged.Layout, merged.Template)
+	}
+
+	if merged.Columns != 4 || merged.Size != "110" || merged.Sort != "-issued" {
+		t.Fatalf("unexpected merged options %+v", merged)
+	}
+
+	if len(merged.Filter.IncludeCategories) != 1 || len(merged.Filter.IncludeIssuers) != 1 {
+		t.Fatalf("expected both filters to be kept, got %+v", merged.Filter)
+	}
+}
+
+func TestSectionRender(t *testing.T) {
+	tt := []struct {
+		name     string
+		section  readme.Section
+		expected string
+		err      bool
+	}{
+		{
+			name: "filtered and sorted",
+			section: readme.Section{
+				Name: "cloud",
+				Options: readme.SectionOptions{
+					Template: `{{ range .Badges }}{{ .Level }}{{ "\n" }}{{ end }}`,
+					Sort:     "level",
+					Filter:   credly.Filter{IncludeIssuers: []string{"The Linux Foundation"}},
+				},
+			},
+			expected: "Foundational\nIntermediate\n",
+		},
+
+		{
+			name: "no matching badges",
+			section: readme.Section{
+				Name: "security",
+				Options: readme.SectionOptions{
+					Layout: readme.LayoutList,
+					Filter: credly.Filter{IncludeIssuers: []string{"Acme"}},
+				},
+			},
+			expected: "",
+		},
+
+		{
+			name: "invalid sort",
+			section: readme.Section{
+				Name:    "cloud",
+				Options: readme.SectionOptions{Sort: "popularity"},
+			},
+			err: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			out, err := tc.section.Render(testBadges)
+			if tc.err {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if out != tc.expected {
+				t.Fatalf("expected %q, got %q", tc.expected, out)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package readme
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+// CommandSigner signs commits with an external program, which is given the
+// commit on stdin and writes an armored signature to stdout, e.g.
+//
+//	gpg --batch --detach-sign --armor --local-user <key id>
+//	ssh-keygen -Y sign -n git -f <private key>
+type CommandSigner struct {
+	Program string
+	Args    []string
+}
+
+// ParseCommandSigner returns the signer running the command line, split on
+// whitespace.
+func ParseCommandSigner(command string) (CommandSigner, error) {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return CommandSigner{}, fmt.Errorf("signing command cannot be empty")
+	}
+
+	return CommandSigner{Program: fields[0], Args: fields[1:]}, nil
+}
+
+// Sign writes the signature of the commit read from r to w.
+func (s CommandSigner) Sign(w io.Writer, r io.Reader) error {
+	var stderr bytes.Buffer
+
+	cmd := exec.Command(s.Program, s.Args...)
+	cmd.Stdin = r
+	cmd.Stdout = w
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to sign commit with %s: %w: %s", s.Program, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return nil
+}
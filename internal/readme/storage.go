@@ -0,0 +1,135 @@
+package readme
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+var (
+	ErrConflict     = errors.New("readme changed since it was fetched")
+	ErrNotFound     = errors.New("not found")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrRateLimited  = errors.New("rate limited")
+)
+
+// File is the content of a readme at a revision of its storage.
+type File struct {
+	Content  string
+	Revision string
+}
+
+// Commit is an updated readme to write to its storage. Revision is the
+// revision the update is based on, the write fails with ErrConflict if the
+// readme has changed since. Changes are the badges added and removed by the
+// update. Storages use their own default for an unset Author or Committer.
+type Commit struct {
+	Content   string
+	Revision  string
+	Message   string
+	Changes   Changes
+	Author    *Identity
+	Committer *Identity
+}
+
+// Storage is where a readme is kept, e.g. a repository on a forge or the
+// local filesystem.
+type Storage interface {
+	// Fetch fetches the readme and its current revision.
+	Fetch(ctx context.Context) (File, error)
+	// FetchFile fetches another file next to the readme, such as a badge
+	// template.
+	FetchFile(ctx context.Context, path string) (string, error)
+	// Write writes the updated readme and returns its new revision.
+	Write(ctx context.Context, commit Commit) (string, error)
+	// Filename returns the path of the readme.
+	Filename() string
+}
+
+// statusError returns the error for a response of a forge API with an error
+// status, wrapping ErrNotFound, ErrUnauthorized or ErrRateLimited where it
+// applies. Message is the error message of the response body, if any.
+func statusError(code int, status, message string) error {
+	text := status
+	if message != "" {
+		text += ": " + message
+	}
+
+	switch {
+	case code == http.StatusTooManyRequests, code == http.StatusForbidden && strings.Contains(strings.ToLower(message), "rate limit"):
+		return fmt.Errorf("%w: %s", ErrRateLimited, text)
+	case code == http.StatusNotFound:
+		return fmt.Errorf("%w: %s", ErrNotFound, text)
+	case code == http.StatusUnauthorized, code == http.StatusForbidden:
+		return fmt.Errorf("%w: %s", ErrUnauthorized, text)
+	}
+
+	return errors.New(text)
+}
+
+// forgeAPI sends requests to the REST API of a forge.
+type forgeAPI struct {
+	client *http.Client
+	// authHeader is set to authValue to authenticate requests, unless
+	// authValue is empty.
+	authHeader string
+	authValue  string
+	// conflict reports whether an error response rejects an update based on
+	// an outdated revision.
+	conflict func(code int, message string) bool
+}
+
+// do sends an authenticated request, responses with an error status are
+// returned as errors.
+func (api forgeAPI) do(ctx context.Context, method, urlString string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, urlString, body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if api.authValue != "" {
+		req.Header.Set(api.authHeader, api.authValue)
+	}
+
+	resp, err := api.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 400 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	var apiErr struct {
+		Message string `json:"message"`
+	}
+	_ = json.NewDecoder(resp.Body).Decode(&apiErr)
+
+	if api.conflict(resp.StatusCode, apiErr.Message) {
+		return nil, ErrConflict
+	}
+
+	return nil, statusError(resp.StatusCode, resp.Status, apiErr.Message)
+}
+
+// decodeContent returns the content of a file as returned by a forge API,
+// decoding base64 encoded content.
+func decodeContent(encoding, content string) (string, error) {
+	if encoding != "base64" {
+		return content, nil
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(content)
+	if err != nil {
+		return "", err
+	}
+
+	return string(decoded), nil
+}